@@ -13,12 +13,23 @@ import (
 	"github.com/AdamShannag/volare/pkg/cloner"
 	"github.com/AdamShannag/volare/pkg/downloader"
 	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/fetcher/azure"
+	"github.com/AdamShannag/volare/pkg/fetcher/bitbucket"
+	"github.com/AdamShannag/volare/pkg/fetcher/ftp"
 	"github.com/AdamShannag/volare/pkg/fetcher/gcs"
 	"github.com/AdamShannag/volare/pkg/fetcher/git"
+	"github.com/AdamShannag/volare/pkg/fetcher/gitea"
 	"github.com/AdamShannag/volare/pkg/fetcher/github"
 	"github.com/AdamShannag/volare/pkg/fetcher/gitlab"
 	httpf "github.com/AdamShannag/volare/pkg/fetcher/http"
+	"github.com/AdamShannag/volare/pkg/fetcher/huggingface"
+	imagef "github.com/AdamShannag/volare/pkg/fetcher/image"
+	"github.com/AdamShannag/volare/pkg/fetcher/inline"
+	"github.com/AdamShannag/volare/pkg/fetcher/kube"
+	"github.com/AdamShannag/volare/pkg/fetcher/oci"
 	"github.com/AdamShannag/volare/pkg/fetcher/s3"
+	"github.com/AdamShannag/volare/pkg/fetcher/sftp"
+	"github.com/AdamShannag/volare/pkg/fetcher/webdav"
 	"github.com/AdamShannag/volare/pkg/types"
 	"github.com/AdamShannag/volare/pkg/utils"
 
@@ -85,6 +96,11 @@ func main() {
 
 	switch mode {
 	case "controller":
+		kubeClient, err := kube.NewClientFactory(masterURL, kubeconfig)()
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		gk := schema.GroupKind{
 			Group: group,
 			Kind:  kind,
@@ -107,7 +123,7 @@ func main() {
 			gvr,
 			mountPath,
 			devicePath,
-			populator.ArgsFactory(mountPath, resources),
+			populator.ArgsFactory(mountPath, resources, kubeClient),
 		)
 
 	case "populator":
@@ -129,15 +145,28 @@ func main() {
 
 		httpClient := &http.Client{Timeout: populatorTimeout}
 		httpDownloader := downloader.NewHTTPDownloader(downloader.WithHTTPClient(httpClient))
+		kubeClientFactory := kube.NewClientFactory(masterURL, kubeconfig)
 
 		registry := fetcher.NewRegistry()
 		err = registry.RegisterAll([]fetcher.RegistryItem{
-			fetcher.NewRegistryItem(types.SourceTypeHTTP, httpf.NewFetcher(httpDownloader, WithLogger(logger, types.SourceTypeHTTP))),
+			fetcher.NewRegistryItem(types.SourceTypeHTTP, httpf.NewFetcher(httpDownloader, WithLogger(logger, types.SourceTypeHTTP), httpf.WithHTTPClient(httpClient))),
 			fetcher.NewRegistryItem(types.SourceTypeGITLAB, gitlab.NewFetcher(httpDownloader, WithLogger(logger, types.SourceTypeGITLAB), gitlab.WithHTTPClient(httpClient))),
 			fetcher.NewRegistryItem(types.SourceTypeGITHUB, github.NewFetcher(httpDownloader, WithLogger(logger, types.SourceTypeGITHUB), github.WithHTTPClient(httpClient))),
 			fetcher.NewRegistryItem(types.SourceTypeS3, s3.NewFetcher(s3.MinioClientFactory, WithLogger(logger, types.SourceTypeS3))),
 			fetcher.NewRegistryItem(types.SourceTypeGIT, git.NewFetcher(cloner.NewGitClonerFactory(), WithLogger(logger, types.SourceTypeGIT))),
 			fetcher.NewRegistryItem(types.SourceTypeGCS, gcs.NewFetcher(gcs.GCSClientFactory, WithLogger(logger, types.SourceTypeGCS))),
+			fetcher.NewRegistryItem(types.SourceTypeGITEA, gitea.NewFetcher(httpDownloader, WithLogger(logger, types.SourceTypeGITEA), gitea.WithHTTPClient(httpClient))),
+			fetcher.NewRegistryItem(types.SourceTypeBITBUCKET, bitbucket.NewFetcher(httpDownloader, WithLogger(logger, types.SourceTypeBITBUCKET), bitbucket.WithHTTPClient(httpClient))),
+			fetcher.NewRegistryItem(types.SourceTypeAZURE, azure.NewFetcher(azure.AzureClientFactory, WithLogger(logger, types.SourceTypeAZURE))),
+			fetcher.NewRegistryItem(types.SourceTypeOCI, oci.NewFetcher(WithLogger(logger, types.SourceTypeOCI))),
+			fetcher.NewRegistryItem(types.SourceTypeIMAGE, imagef.NewFetcher(WithLogger(logger, types.SourceTypeIMAGE))),
+			fetcher.NewRegistryItem(types.SourceTypeHUGGINGFACE, huggingface.NewFetcher(httpDownloader, WithLogger(logger, types.SourceTypeHUGGINGFACE), huggingface.WithHTTPClient(httpClient))),
+			fetcher.NewRegistryItem(types.SourceTypeSFTP, sftp.NewFetcher(sftp.SFTPClientFactory, WithLogger(logger, types.SourceTypeSFTP))),
+			fetcher.NewRegistryItem(types.SourceTypeFTP, ftp.NewFetcher(ftp.FTPClientFactory, WithLogger(logger, types.SourceTypeFTP))),
+			fetcher.NewRegistryItem(types.SourceTypeWEBDAV, webdav.NewFetcher(httpDownloader, WithLogger(logger, types.SourceTypeWEBDAV), webdav.WithHTTPClient(httpClient))),
+			fetcher.NewRegistryItem(types.SourceTypeINLINE, inline.NewFetcher(WithLogger(logger, types.SourceTypeINLINE))),
+			fetcher.NewRegistryItem(types.SourceTypeCONFIGMAP, kube.NewConfigMapFetcher(kubeClientFactory, WithLogger(logger, types.SourceTypeCONFIGMAP))),
+			fetcher.NewRegistryItem(types.SourceTypeSECRET, kube.NewSecretFetcher(kubeClientFactory, WithLogger(logger, types.SourceTypeSECRET))),
 		})
 
 		if err != nil {
@@ -8,15 +8,21 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/AdamShannag/volare/pkg/extract"
 	"github.com/AdamShannag/volare/pkg/fetcher"
 	"github.com/AdamShannag/volare/pkg/types"
 	"github.com/AdamShannag/volare/pkg/utils"
 	"github.com/AdamShannag/volare/pkg/workerpool"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 )
 
-func ArgsFactory(mountPath, resources string) func(_ bool, u *unstructured.Unstructured) ([]string, error) {
+const defaultServiceAccount = "default"
+
+func ArgsFactory(mountPath, resources string, client kubernetes.Interface) func(_ bool, u *unstructured.Unstructured) ([]string, error) {
 	return func(_ bool, u *unstructured.Unstructured) ([]string, error) {
 		var vp types.VolarePopulator
 		var args []string
@@ -27,6 +33,16 @@ func ArgsFactory(mountPath, resources string) func(_ bool, u *unstructured.Unstr
 			return args, err
 		}
 
+		if err = scopeToNamespace(vp.Spec.Sources, u.GetNamespace()); err != nil {
+			slog.Error("invalid VolarePopulator sources", "error", err)
+			return args, err
+		}
+
+		if err = authorizeSources(context.Background(), client, vp.Spec.Sources, u.GetNamespace()); err != nil {
+			slog.Error("unauthorized VolarePopulator sources", "error", err)
+			return args, err
+		}
+
 		specBytes, err := json.Marshal(vp.Spec)
 		if err != nil {
 			slog.Error("failed to marshal VolarePopulator.Spec to JSON", "error", err)
@@ -64,6 +80,63 @@ func ArgsFactory(mountPath, resources string) func(_ bool, u *unstructured.Unstr
 	}
 }
 
+// scopeToNamespace pins configmap and secret sources to the namespace of the VolarePopulator, so that the
+// populator cannot be used to copy objects out of other namespaces.
+func scopeToNamespace(sources []types.Source, namespace string) error {
+	for i := range sources {
+		for _, opts := range []*types.KubeObjectOptions{sources[i].ConfigMap, sources[i].Secret} {
+			if opts == nil {
+				continue
+			}
+			if opts.Namespace != "" && opts.Namespace != namespace {
+				return fmt.Errorf("source %q may only read objects from namespace %q, got %q", opts.Name, namespace, opts.Namespace)
+			}
+			opts.Namespace = namespace
+		}
+	}
+	return nil
+}
+
+// authorizeSources runs a SubjectAccessReview for the default service account of the VolarePopulator's namespace
+// against every configmap and secret source, so that creating a VolarePopulator only copies objects the namespace
+// has explicitly shared with that account.
+func authorizeSources(ctx context.Context, client kubernetes.Interface, sources []types.Source, namespace string) error {
+	for _, src := range sources {
+		for _, object := range []struct {
+			resource string
+			opts     *types.KubeObjectOptions
+		}{{"configmaps", src.ConfigMap}, {"secrets", src.Secret}} {
+			resource, opts := object.resource, object.opts
+			if opts == nil {
+				continue
+			}
+			if client == nil {
+				return fmt.Errorf("cannot authorize %s %q without a kubernetes client", resource, opts.Name)
+			}
+
+			review, err := client.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					User:   fmt.Sprintf("system:serviceaccount:%s:%s", namespace, defaultServiceAccount),
+					Groups: []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"},
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: namespace,
+						Verb:      "get",
+						Resource:  resource,
+						Name:      opts.Name,
+					},
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to review access to %s %s/%s: %w", resource, namespace, opts.Name, err)
+			}
+			if !review.Status.Allowed {
+				return fmt.Errorf("service account %s/%s is not allowed to get %s %q", namespace, defaultServiceAccount, resource, opts.Name)
+			}
+		}
+	}
+	return nil
+}
+
 func Populate(ctx context.Context, specs string, mountPath string, registry *fetcher.Registry) error {
 	spec, err := parseSpecs(specs)
 	if err != nil {
@@ -102,7 +175,7 @@ func processSource(registry *fetcher.Registry, mountPath string) func(context.Co
 			return nil
 		}
 
-		err = workerpool.RunPool(ctx, object.Objects, object.Workers, object.Processor)
+		err = workerpool.RunPool(ctx, object.Objects, object.Workers, extract.Processor(src, object.Processor))
 		if err != nil {
 			return err
 		}
@@ -153,6 +226,54 @@ func validateSourceConfig(src types.Source) error {
 			field: func(s types.Source) any { return s.GCS },
 			label: "gcs",
 		},
+		types.SourceTypeGITEA: {
+			field: func(s types.Source) any { return s.Gitea },
+			label: "gitea",
+		},
+		types.SourceTypeBITBUCKET: {
+			field: func(s types.Source) any { return s.Bitbucket },
+			label: "bitbucket",
+		},
+		types.SourceTypeAZURE: {
+			field: func(s types.Source) any { return s.Azure },
+			label: "azure",
+		},
+		types.SourceTypeOCI: {
+			field: func(s types.Source) any { return s.OCI },
+			label: "oci",
+		},
+		types.SourceTypeIMAGE: {
+			field: func(s types.Source) any { return s.Image },
+			label: "image",
+		},
+		types.SourceTypeHUGGINGFACE: {
+			field: func(s types.Source) any { return s.HuggingFace },
+			label: "huggingface",
+		},
+		types.SourceTypeSFTP: {
+			field: func(s types.Source) any { return s.SFTP },
+			label: "sftp",
+		},
+		types.SourceTypeFTP: {
+			field: func(s types.Source) any { return s.FTP },
+			label: "ftp",
+		},
+		types.SourceTypeWEBDAV: {
+			field: func(s types.Source) any { return s.WebDAV },
+			label: "webdav",
+		},
+		types.SourceTypeINLINE: {
+			field: func(s types.Source) any { return s.Inline },
+			label: "inline",
+		},
+		types.SourceTypeCONFIGMAP: {
+			field: func(s types.Source) any { return s.ConfigMap },
+			label: "configmap",
+		},
+		types.SourceTypeSECRET: {
+			field: func(s types.Source) any { return s.Secret },
+			label: "secret",
+		},
 	}
 
 	if check, ok := checks[src.Type]; ok {
@@ -1,21 +1,32 @@
 package populator_test
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
 
 	"github.com/AdamShannag/volare/internal/populator"
 	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/fetcher/inline"
 	"github.com/AdamShannag/volare/pkg/types"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 type mockFetcher struct {
@@ -118,7 +129,7 @@ func TestArgsFactory_Success(t *testing.T) {
 	u := &unstructured.Unstructured{Object: unstructuredMap}
 
 	mountPath := "/mnt/test"
-	argsFunc := populator.ArgsFactory(mountPath, "")
+	argsFunc := populator.ArgsFactory(mountPath, "", nil)
 
 	args, err := argsFunc(false, u)
 	if err != nil {
@@ -159,7 +170,7 @@ func TestArgsFactory_InvalidUnstructured(t *testing.T) {
 		},
 	}
 
-	argsFunc := populator.ArgsFactory("/mnt/test", "")
+	argsFunc := populator.ArgsFactory("/mnt/test", "", nil)
 
 	args, err := argsFunc(false, u)
 	if err == nil {
@@ -203,7 +214,7 @@ func TestArgsFactory_WithResources(t *testing.T) {
 	u := &unstructured.Unstructured{Object: unstructuredMap}
 
 	mountPath := "/mnt/test"
-	argsFunc := populator.ArgsFactory(mountPath, tempDir)
+	argsFunc := populator.ArgsFactory(mountPath, tempDir, nil)
 
 	args, err := argsFunc(false, u)
 	if err != nil {
@@ -271,3 +282,148 @@ func newValidSpec(t *testing.T) string {
 	}
 	return string(specBytes)
 }
+
+func TestArgsFactory_ScopesKubeSourcesToNamespace(t *testing.T) {
+	t.Parallel()
+
+	newUnstructured := func(sources ...types.Source) *unstructured.Unstructured {
+		vp := types.VolarePopulator{
+			TypeMeta:   metav1.TypeMeta{Kind: "VolarePopulator", APIVersion: "volare/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "test-populator", Namespace: "team-a"},
+			Spec:       types.VolarePopulatorSpec{Sources: sources},
+		}
+		unstructuredMap, err := toUnstructured(vp)
+		if err != nil {
+			t.Fatalf("failed to convert to unstructured: %v", err)
+		}
+		return &unstructured.Unstructured{Object: unstructuredMap}
+	}
+
+	argsFunc := populator.ArgsFactory("/mnt/test", "", reviewClient(func(*authorizationv1.SubjectAccessReview) bool { return true }))
+	args, err := argsFunc(false, newUnstructured(
+		types.Source{Type: types.SourceTypeCONFIGMAP, TargetPath: "config", ConfigMap: &types.KubeObjectOptions{Name: "app"}},
+		types.Source{Type: types.SourceTypeSECRET, TargetPath: "secret", Secret: &types.KubeObjectOptions{Name: "db", Namespace: "team-a"}},
+	))
+	if err != nil {
+		t.Fatalf("ArgsFactory returned error: %v", err)
+	}
+
+	var spec types.VolarePopulatorSpec
+	if err = json.Unmarshal([]byte(strings.TrimPrefix(args[1], "--spec=")), &spec); err != nil {
+		t.Fatalf("failed to decode spec: %v", err)
+	}
+	if spec.Sources[0].ConfigMap.Namespace != "team-a" || spec.Sources[1].Secret.Namespace != "team-a" {
+		t.Errorf("expected sources to be scoped to team-a, got %+v %+v", spec.Sources[0].ConfigMap, spec.Sources[1].Secret)
+	}
+
+	_, err = argsFunc(false, newUnstructured(
+		types.Source{Type: types.SourceTypeSECRET, TargetPath: "secret", Secret: &types.KubeObjectOptions{Name: "db", Namespace: "kube-system"}},
+	))
+	if err == nil || !strings.Contains(err.Error(), `may only read objects from namespace "team-a"`) {
+		t.Errorf("expected cross-namespace source to be rejected, got %v", err)
+	}
+}
+
+// reviewClient answers SubjectAccessReviews with allow.
+func reviewClient(allow func(*authorizationv1.SubjectAccessReview) bool) *fake.Clientset {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		review.Status.Allowed = allow(review)
+		return true, review, nil
+	})
+	return client
+}
+
+func TestArgsFactory_AuthorizesKubeSources(t *testing.T) {
+	t.Parallel()
+
+	vp := types.VolarePopulator{
+		TypeMeta:   metav1.TypeMeta{Kind: "VolarePopulator", APIVersion: "volare/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-populator", Namespace: "team-a"},
+		Spec: types.VolarePopulatorSpec{Sources: []types.Source{
+			{Type: types.SourceTypeCONFIGMAP, TargetPath: "config", ConfigMap: &types.KubeObjectOptions{Name: "app"}},
+			{Type: types.SourceTypeSECRET, TargetPath: "secret", Secret: &types.KubeObjectOptions{Name: "db"}},
+		}},
+	}
+	unstructuredMap, err := toUnstructured(vp)
+	if err != nil {
+		t.Fatalf("failed to convert to unstructured: %v", err)
+	}
+	u := &unstructured.Unstructured{Object: unstructuredMap}
+
+	var mu sync.Mutex
+	var reviews []string
+	allowed := func(review *authorizationv1.SubjectAccessReview) bool {
+		attrs := review.Spec.ResourceAttributes
+		mu.Lock()
+		reviews = append(reviews, strings.Join([]string{review.Spec.User, attrs.Verb, attrs.Namespace, attrs.Resource, attrs.Name}, " "))
+		mu.Unlock()
+		return attrs.Resource == "configmaps"
+	}
+
+	_, err = populator.ArgsFactory("/mnt/test", "", reviewClient(allowed))(false, u)
+	if err == nil || !strings.Contains(err.Error(), `service account team-a/default is not allowed to get secrets "db"`) {
+		t.Fatalf("expected secret source to be rejected, got %v", err)
+	}
+
+	want := []string{
+		"system:serviceaccount:team-a:default get team-a configmaps app",
+		"system:serviceaccount:team-a:default get team-a secrets db",
+	}
+	if !slices.Equal(reviews, want) {
+		t.Errorf("expected reviews %v, got %v", want, reviews)
+	}
+
+	if _, err = populator.ArgsFactory("/mnt/test", "", nil)(false, u); err == nil {
+		t.Error("expected kube sources to be rejected without a client")
+	}
+}
+
+func TestPopulate_ExtractsArchives(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{"model-v1/config.json": "{}", "model-v1/weights.bin": "w"} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := errors.Join(tw.Close(), gz.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := fetcher.NewRegistry()
+	_ = reg.Register(types.SourceTypeINLINE, inline.NewFetcher(slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	specBytes, err := json.Marshal(types.VolarePopulatorSpec{
+		Sources: []types.Source{{
+			Type:       types.SourceTypeINLINE,
+			TargetPath: "models/model.tar.gz",
+			Inline:     &types.InlineOptions{Content: base64.StdEncoding.EncodeToString(buf.Bytes()), Encoding: types.InlineEncodingBase64},
+			Extract:    &types.ExtractOptions{StripComponents: 1, Exclude: []string{"*.bin"}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mountPath := t.TempDir()
+	if err = populator.Populate(context.Background(), string(specBytes), mountPath, reg); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+
+	if data, readErr := os.ReadFile(filepath.Join(mountPath, "models", "config.json")); readErr != nil || string(data) != "{}" {
+		t.Errorf("expected extracted config.json, got %q (%v)", data, readErr)
+	}
+	for _, name := range []string{"model.tar.gz", "weights.bin"} {
+		if _, statErr := os.Stat(filepath.Join(mountPath, "models", name)); !errors.Is(statErr, os.ErrNotExist) {
+			t.Errorf("expected %s not to be written, got %v", name, statErr)
+		}
+	}
+}
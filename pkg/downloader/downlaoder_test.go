@@ -1,16 +1,22 @@
 package downloader_test
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/AdamShannag/volare/pkg/downloader"
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/klauspost/compress/zstd"
 )
 
 func TestHTTPDownloader_Download(t *testing.T) {
@@ -74,3 +80,159 @@ func TestHTTPDownloader_Download_InvalidURL(t *testing.T) {
 		t.Fatalf("Expected error for invalid URL, got: %v", err)
 	}
 }
+
+func TestShouldDownloadRanges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     *types.RangedDownloadOptions
+		size     int64
+		expected bool
+	}{
+		{name: "disabled", opts: nil, size: 1 << 40, expected: false},
+		{name: "below default threshold", opts: &types.RangedDownloadOptions{}, size: downloader.DefaultRangeThreshold - 1, expected: false},
+		{name: "at default threshold", opts: &types.RangedDownloadOptions{}, size: downloader.DefaultRangeThreshold, expected: true},
+		{name: "custom threshold", opts: &types.RangedDownloadOptions{Threshold: 10}, size: 11, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downloader.ShouldDownloadRanges(tt.opts, tt.size); got != tt.expected {
+				t.Errorf("ShouldDownloadRanges() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDownloadRanges(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(strings.Repeat("abcdefghij", 25))
+	destFile := filepath.Join(t.TempDir(), "nested", "large.bin")
+
+	var mu sync.Mutex
+	var ranges [][2]int64
+	workers := 3
+	err := downloader.DownloadRanges(context.Background(), types.RangedDownloadOptions{PartSize: 64, Workers: &workers}, int64(len(content)), destFile,
+		func(_ context.Context, offset, length int64) (io.ReadCloser, error) {
+			mu.Lock()
+			ranges = append(ranges, [2]int64{offset, length})
+			mu.Unlock()
+			return io.NopCloser(bytes.NewReader(content[offset : offset+length])), nil
+		})
+	if err != nil {
+		t.Fatalf("DownloadRanges failed: %v", err)
+	}
+
+	data, err := os.ReadFile(destFile)
+	if err != nil {
+		t.Fatalf("Reading downloaded file failed: %v", err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("content mismatch: got %q", data)
+	}
+	if len(ranges) != 4 {
+		t.Errorf("expected 4 ranges, got %v", ranges)
+	}
+}
+
+func TestDownloadRanges_ShortRead(t *testing.T) {
+	t.Parallel()
+
+	destFile := filepath.Join(t.TempDir(), "short.bin")
+	err := downloader.DownloadRanges(context.Background(), types.RangedDownloadOptions{PartSize: 10}, 20, destFile,
+		func(_ context.Context, _, _ int64) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("abc")), nil
+		})
+	if err == nil || !strings.Contains(err.Error(), "short read") {
+		t.Fatalf("expected short read error, got %v", err)
+	}
+}
+
+func TestDownloadRanges_InvalidWorkers(t *testing.T) {
+	t.Parallel()
+
+	destFile := filepath.Join(t.TempDir(), "zero.bin")
+	workers := 0
+	err := downloader.DownloadRanges(context.Background(), types.RangedDownloadOptions{PartSize: 10, Workers: &workers}, 20, destFile,
+		func(_ context.Context, _, _ int64) (io.ReadCloser, error) {
+			t.Fatal("no range should be opened")
+			return nil, nil
+		})
+	if err == nil || !strings.Contains(err.Error(), "at least 1") {
+		t.Fatalf("expected invalid workers error, got %v", err)
+	}
+	if _, statErr := os.Stat(destFile); !os.IsNotExist(statErr) {
+		t.Errorf("expected %q not to be created, got %v", destFile, statErr)
+	}
+}
+
+func TestHTTPDownloader_Download_Decompress(t *testing.T) {
+	t.Parallel()
+
+	const fileContent = "hello world"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		zw, _ := zstd.NewWriter(w)
+		_, _ = io.WriteString(zw, fileContent)
+		_ = zw.Close()
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	d := downloader.NewHTTPDownloader()
+
+	download := extract.Processor(types.Source{Decompress: true}, func(ctx context.Context, j types.ObjectToDownload) error {
+		return d.Download(ctx, j.ActualPath, nil, j.Path)
+	})
+	if err := download(context.Background(), types.ObjectToDownload{ActualPath: server.URL, Path: filepath.Join(tmpDir, "test.txt.zst")}); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "test.txt"))
+	if err != nil {
+		t.Fatalf("Reading decompressed file failed: %v", err)
+	}
+	if got := string(data); got != fileContent {
+		t.Errorf("Expected file content %q, got %q", fileContent, got)
+	}
+}
+
+func TestHTTPDownloader_Download_DecompressAfterDroppedConnection(t *testing.T) {
+	t.Parallel()
+
+	const fileContent = "hello world, served twice"
+	var buf bytes.Buffer
+	zw, _ := zstd.NewWriter(&buf)
+	_, _ = io.WriteString(zw, fileContent)
+	_ = zw.Close()
+	compressed := buf.Bytes()
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(compressed)))
+		_, _ = w.Write(compressed[:len(compressed)/2])
+	}))
+	defer primary.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(compressed)
+	}))
+	defer mirror.Close()
+
+	tmpDir := t.TempDir()
+	d := downloader.NewHTTPDownloader()
+
+	download := extract.Processor(types.Source{Decompress: true}, func(ctx context.Context, j types.ObjectToDownload) error {
+		if err := d.Download(ctx, primary.URL, nil, j.Path); err == nil {
+			t.Error("expected the truncated download to fail")
+		}
+		return d.Download(ctx, mirror.URL, nil, j.Path)
+	})
+	if err := download(context.Background(), types.ObjectToDownload{Path: filepath.Join(tmpDir, "test.txt.zst")}); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "test.txt"))
+	if err != nil || string(data) != fileContent {
+		t.Errorf("Expected file content %q, got %q (%v)", fileContent, data, err)
+	}
+}
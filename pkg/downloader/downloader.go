@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+
+	"github.com/AdamShannag/volare/pkg/extract"
 )
 
 type Downloader interface {
@@ -64,7 +66,7 @@ func (d *HTTPDownloader) Download(ctx context.Context, url string, headers map[s
 		return fmt.Errorf("failed to create directory for %q: %w", destPath, err)
 	}
 
-	outFile, err := os.Create(destPath)
+	outFile, err := extract.Create(ctx, destPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file %q: %w", destPath, err)
 	}
@@ -76,6 +78,7 @@ func (d *HTTPDownloader) Download(ctx context.Context, url string, headers map[s
 	}()
 
 	if _, err = io.Copy(outFile, resp.Body); err != nil {
+		extract.Abort(outFile, err)
 		return fmt.Errorf("failed to write file %q: %w", destPath, err)
 	}
 
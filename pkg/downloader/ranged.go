@@ -0,0 +1,99 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/workerpool"
+)
+
+const (
+	DefaultPartSize       int64 = 64 << 20
+	DefaultRangeThreshold int64 = 256 << 20
+	DefaultRangeWorkers         = 4
+)
+
+type RangeOpener func(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+
+type byteRange struct {
+	offset int64
+	length int64
+}
+
+func ShouldDownloadRanges(opts *types.RangedDownloadOptions, size int64) bool {
+	if opts == nil {
+		return false
+	}
+
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = DefaultRangeThreshold
+	}
+	return size >= threshold
+}
+
+// DownloadRanges preallocates destPath to size bytes and fills it with byte ranges
+// fetched concurrently through open.
+func DownloadRanges(ctx context.Context, opts types.RangedDownloadOptions, size int64, destPath string, open RangeOpener) error {
+	partSize := opts.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+
+	workers := DefaultRangeWorkers
+	if opts.Workers != nil {
+		workers = *opts.Workers
+	}
+	if workers < 1 {
+		return fmt.Errorf("ranged workers must be at least 1, got %d", workers)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", destPath, err)
+	}
+
+	outFile, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", destPath, err)
+	}
+	defer func() {
+		if cerr := outFile.Close(); cerr != nil {
+			slog.Warn("error closing file", "error", cerr)
+		}
+	}()
+
+	if err = outFile.Truncate(size); err != nil {
+		return fmt.Errorf("failed to preallocate file %q: %w", destPath, err)
+	}
+
+	var parts []byteRange
+	for offset := int64(0); offset < size; offset += partSize {
+		parts = append(parts, byteRange{offset: offset, length: min(partSize, size-offset)})
+	}
+
+	return workerpool.RunPool(ctx, parts, &workers, func(ctx context.Context, part byteRange) error {
+		reader, openErr := open(ctx, part.offset, part.length)
+		if openErr != nil {
+			return fmt.Errorf("failed to open range %d-%d: %w", part.offset, part.offset+part.length-1, openErr)
+		}
+		defer func() {
+			if cerr := reader.Close(); cerr != nil {
+				slog.Warn("error closing range reader", "error", cerr)
+			}
+		}()
+
+		n, copyErr := io.Copy(io.NewOffsetWriter(outFile, part.offset), io.LimitReader(reader, part.length))
+		if copyErr != nil {
+			return fmt.Errorf("failed to write range %d-%d to %q: %w", part.offset, part.offset+part.length-1, destPath, copyErr)
+		}
+		if n != part.length {
+			return fmt.Errorf("short read for range %d-%d of %q: got %d bytes", part.offset, part.offset+part.length-1, destPath, n)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+package extract
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+var decoders = map[string]func(io.Reader) (io.ReadCloser, error){
+	".gz": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	".bz2": func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	},
+	".xz": func(r io.Reader) (io.ReadCloser, error) {
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	},
+	".zst": func(r io.Reader) (io.ReadCloser, error) {
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	},
+}
+
+// DecompressedName returns name without its compression suffix, e.g. data.csv for data.csv.gz, and the
+// suffix itself.
+func DecompressedName(name string) (string, string, bool) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if _, ok := decoders[ext]; !ok {
+		return "", "", false
+	}
+	trimmed := name[:len(name)-len(ext)]
+	if trimmed == "" || strings.HasSuffix(trimmed, string(filepath.Separator)) {
+		return "", "", false
+	}
+	return trimmed, ext, true
+}
+
+// NewReader decodes r according to a compression suffix returned by DecompressedName.
+func NewReader(r io.Reader, ext string) (io.ReadCloser, error) {
+	return decoders[ext](r)
+}
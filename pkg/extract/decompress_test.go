@@ -0,0 +1,160 @@
+package extract_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+func TestDecompressedName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		target string
+		ok     bool
+	}{
+		{name: "data/train.csv.gz", target: "data/train.csv", ok: true},
+		{name: "dump.SQL.BZ2", target: "dump.SQL", ok: true},
+		{name: "weights.bin.xz", target: "weights.bin", ok: true},
+		{name: "corpus.jsonl.zst", target: "corpus.jsonl", ok: true},
+		{name: "archive.tar.gz", target: "archive.tar", ok: true},
+		{name: "data/.gz", ok: false},
+		{name: "data.csv", ok: false},
+	}
+
+	for _, tt := range tests {
+		target, _, ok := extract.DecompressedName(tt.name)
+		if ok != tt.ok || target != tt.target {
+			t.Errorf("DecompressedName(%q) = %q, %v; want %q, %v", tt.name, target, ok, tt.target, tt.ok)
+		}
+	}
+}
+
+func TestProcessor_Decompress(t *testing.T) {
+	t.Parallel()
+
+	const content = "id,label\n1,cat\n2,dog\n"
+	files := map[string][]byte{
+		"train.csv.gz":  compress(t, []byte(content), extract.FormatTarGzip),
+		"valid.csv.xz":  compress(t, []byte(content), extract.FormatTarXz),
+		"test.csv.zst":  compress(t, []byte(content), extract.FormatTarZstd),
+		"labels.txt":    []byte(content),
+		"bundle.tar.gz": compress(t, makeTar(t, sample), extract.FormatTarGzip),
+	}
+
+	dir := t.TempDir()
+	processor := extract.Processor(types.Source{Decompress: true}, func(ctx context.Context, j types.ObjectToDownload) error {
+		return extract.WriteFile(ctx, j.Path, files[j.ActualPath], 0o644)
+	})
+	for name := range files {
+		if err := processor(context.Background(), types.ObjectToDownload{ActualPath: name, Path: filepath.Join(dir, name)}); err != nil {
+			t.Fatalf("processor failed for %q: %v", name, err)
+		}
+	}
+
+	got := listFiles(t, dir)
+	for _, name := range []string{"train.csv", "valid.csv", "test.csv", "labels.txt"} {
+		if got[name] != content {
+			t.Errorf("expected %q to be decompressed, got %q", name, got[name])
+		}
+	}
+	if tarball, ok := got["bundle.tar"]; !ok || !bytes.Equal([]byte(tarball), makeTar(t, sample)) {
+		t.Error("expected bundle.tar.gz to be decompressed to bundle.tar without extract")
+	}
+	if len(got) != 5 {
+		t.Errorf("expected 5 files, got %v", got)
+	}
+}
+
+func TestProcessor_DecompressCorrupt(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	processor := extract.Processor(types.Source{Decompress: true}, func(ctx context.Context, j types.ObjectToDownload) error {
+		fh, err := extract.Create(ctx, j.Path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = fh.Close()
+		}()
+		_, err = io.Copy(fh, strings.NewReader("this is not a gzip stream"))
+		return err
+	})
+
+	err := processor(context.Background(), types.ObjectToDownload{Path: filepath.Join(dir, "data.csv.gz")})
+	if err == nil || !errors.Is(err, gzip.ErrHeader) {
+		t.Errorf("expected gzip header error, got %v", err)
+	}
+	for _, name := range []string{"data.csv.gz", "data.csv"} {
+		if _, statErr := os.Stat(filepath.Join(dir, name)); !errors.Is(statErr, os.ErrNotExist) {
+			t.Errorf("expected %s not to be left behind, got %v", name, statErr)
+		}
+	}
+}
+
+func TestProcessor_AbortedAttempt(t *testing.T) {
+	t.Parallel()
+
+	const content = "id,label\n1,cat\n2,dog\n"
+	data := compress(t, []byte(content), extract.FormatTarGzip)
+
+	dir := t.TempDir()
+	processor := extract.Processor(types.Source{Decompress: true}, func(ctx context.Context, j types.ObjectToDownload) error {
+		// The first attempt is cut off halfway and abandoned, like a failed mirror.
+		partial, err := extract.Create(ctx, j.Path)
+		if err != nil {
+			return err
+		}
+		_, _ = partial.Write(data[:len(data)/2])
+		extract.Abort(partial, io.ErrUnexpectedEOF)
+		if err = partial.Close(); err != nil {
+			t.Errorf("expected Close after Abort to succeed, got %v", err)
+		}
+
+		return extract.WriteFile(ctx, j.Path, data, 0o644)
+	})
+
+	if err := processor(context.Background(), types.ObjectToDownload{Path: filepath.Join(dir, "data.csv.gz")}); err != nil {
+		t.Fatalf("expected the second attempt to succeed, got %v", err)
+	}
+	if got := listFiles(t, dir); len(got) != 1 || got["data.csv"] != content {
+		t.Errorf("unexpected files %v", got)
+	}
+}
+
+func TestProcessor_CloseReportsTruncatedStream(t *testing.T) {
+	t.Parallel()
+
+	data := compress(t, []byte("id,label\n1,cat\n"), extract.FormatTarGzip)
+
+	dir := t.TempDir()
+	var closeErr error
+	processor := extract.Processor(types.Source{Decompress: true}, func(ctx context.Context, j types.ObjectToDownload) error {
+		fh, err := extract.Create(ctx, j.Path)
+		if err != nil {
+			return err
+		}
+		_, _ = fh.Write(data[:len(data)/2])
+		closeErr = fh.Close()
+		return nil
+	})
+
+	err := processor(context.Background(), types.ObjectToDownload{Path: filepath.Join(dir, "data.csv.gz")})
+	if !errors.Is(closeErr, io.ErrUnexpectedEOF) {
+		t.Errorf("expected Close to report the truncated stream, got %v", closeErr)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected processor to report the truncated stream, got %v", err)
+	}
+}
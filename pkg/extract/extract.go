@@ -0,0 +1,248 @@
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+type Format string
+
+const (
+	FormatTar      Format = "tar"
+	FormatTarGzip  Format = "tar.gz"
+	FormatTarBzip2 Format = "tar.bz2"
+	FormatTarXz    Format = "tar.xz"
+	FormatTarZstd  Format = "tar.zst"
+	FormatZip      Format = "zip"
+)
+
+var suffixes = []struct {
+	suffix string
+	format Format
+}{
+	{".tar.gz", FormatTarGzip},
+	{".tgz", FormatTarGzip},
+	{".tar.bz2", FormatTarBzip2},
+	{".tbz2", FormatTarBzip2},
+	{".tar.xz", FormatTarXz},
+	{".txz", FormatTarXz},
+	{".tar.zst", FormatTarZstd},
+	{".tzst", FormatTarZstd},
+	{".tar", FormatTar},
+	{".zip", FormatZip},
+}
+
+// Detect returns the archive format of name based on its extension.
+func Detect(name string) (Format, bool) {
+	lower := strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return s.format, true
+		}
+	}
+	return "", false
+}
+
+// Extract unpacks the archive read from r into dir. Entries that would land outside dir are rejected, and
+// links and special files are skipped. Zip archives are buffered in a temporary file first, because their
+// index is stored at the end.
+func Extract(r io.Reader, format Format, dir string, opts types.ExtractOptions) error {
+	if err := utils.ValidatePatterns(opts.Include, opts.Exclude); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", dir, err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory %q: %w", dir, err)
+	}
+	defer func() {
+		_ = root.Close()
+	}()
+
+	e := &extractor{root: root, opts: opts}
+	if format == FormatZip {
+		return e.zip(r)
+	}
+
+	tr, err := newTarReader(r, format)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tr.Close()
+	}()
+	return e.tar(tr)
+}
+
+func newTarReader(r io.Reader, format Format) (io.ReadCloser, error) {
+	switch format {
+	case FormatTar:
+		return io.NopCloser(r), nil
+	case FormatTarGzip:
+		return NewReader(r, ".gz")
+	case FormatTarBzip2:
+		return NewReader(r, ".bz2")
+	case FormatTarXz:
+		return NewReader(r, ".xz")
+	case FormatTarZstd:
+		return NewReader(r, ".zst")
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+type extractor struct {
+	root *os.Root
+	opts types.ExtractOptions
+}
+
+func (e *extractor) tar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar entry: %w", err)
+		}
+
+		target, ok, err := e.target(hdr.Name, hdr.Typeflag == tar.TypeDir)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = e.mkdirAll(target)
+		case tar.TypeReg:
+			err = e.writeFile(target, tr, hdr.FileInfo().Mode().Perm())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (e *extractor) zip(r io.Reader) error {
+	tmp, err := os.CreateTemp("", "volare-zip-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return fmt.Errorf("failed to buffer zip archive: %w", err)
+	}
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %w", err)
+	}
+
+	for _, f := range zr.File {
+		info := f.FileInfo()
+		target, ok, err := e.target(f.Name, info.IsDir())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		switch {
+		case info.IsDir():
+			err = e.mkdirAll(target)
+		case info.Mode().IsRegular():
+			err = e.writeZipFile(target, f)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *extractor) writeZipFile(target string, f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open zip entry %q: %w", f.Name, err)
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+	return e.writeFile(target, rc, f.Mode().Perm())
+}
+
+// target maps an archive entry to its path below the root. It reports false for entries that are removed by
+// stripComponents or filtered out by the include and exclude patterns.
+func (e *extractor) target(name string, isDir bool) (string, bool, error) {
+	clean := path.Clean(name)
+	if clean == "." {
+		return "", false, nil
+	}
+	if !filepath.IsLocal(clean) {
+		return "", false, fmt.Errorf("archive entry %q is outside the target directory", name)
+	}
+
+	parts := strings.Split(clean, "/")
+	if len(parts) <= e.opts.StripComponents {
+		return "", false, nil
+	}
+	rel := strings.Join(parts[e.opts.StripComponents:], "/")
+	if !isDir && !utils.MatchPatterns(rel, e.opts.Include, e.opts.Exclude) {
+		return "", false, nil
+	}
+	return filepath.FromSlash(rel), true, nil
+}
+
+func (e *extractor) mkdirAll(name string) error {
+	dir := ""
+	for _, part := range strings.Split(filepath.ToSlash(name), "/") {
+		dir = filepath.Join(dir, part)
+		if err := e.root.Mkdir(dir, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("failed to create directory %q: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+func (e *extractor) writeFile(name string, r io.Reader, perm fs.FileMode) error {
+	if dir := filepath.Dir(name); dir != "." {
+		if err := e.mkdirAll(dir); err != nil {
+			return err
+		}
+	}
+
+	fh, err := e.root.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm|0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", name, err)
+	}
+	if _, err = io.Copy(fh, r); err != nil {
+		_ = fh.Close()
+		return fmt.Errorf("failed to write file %q: %w", name, err)
+	}
+	if err = fh.Close(); err != nil {
+		return fmt.Errorf("failed to close file %q: %w", name, err)
+	}
+	return nil
+}
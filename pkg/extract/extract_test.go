@@ -0,0 +1,341 @@
+package extract_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+type entry struct {
+	name    string
+	content string
+	dir     bool
+	link    string
+}
+
+var sample = []entry{
+	{name: "release-1.0/", dir: true},
+	{name: "release-1.0/README.md", content: "readme"},
+	{name: "release-1.0/bin/tool", content: "binary"},
+	{name: "release-1.0/docs/guide.txt", content: "guide"},
+	{name: "release-1.0/latest", link: "bin/tool"},
+}
+
+func makeTar(t *testing.T, entries []entry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		switch {
+		case e.dir:
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0o755
+		case e.link != "":
+			hdr.Typeflag, hdr.Linkname = tar.TypeSymlink, e.link
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		if _, err := io.WriteString(tw, e.content); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func compress(t *testing.T, data []byte, format extract.Format) []byte {
+	t.Helper()
+
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+		err error
+	)
+	switch format {
+	case extract.FormatTarGzip:
+		w = gzip.NewWriter(&buf)
+	case extract.FormatTarXz:
+		w, err = xz.NewWriter(&buf)
+	case extract.FormatTarZstd:
+		w, err = zstd.NewWriter(&buf)
+	default:
+		return data
+	}
+	if err != nil {
+		t.Fatalf("failed to create compressor: %v", err)
+	}
+	if _, err = w.Write(data); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("failed to close compressor: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T, entries []entry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		if e.link != "" {
+			continue
+		}
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatalf("failed to create zip entry: %v", err)
+		}
+		if _, err = io.WriteString(w, e.content); err != nil {
+			t.Fatalf("failed to write zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func listFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk %q: %v", dir, err)
+	}
+	return files
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]extract.Format{
+		"data.tar.gz":    extract.FormatTarGzip,
+		"DATA.TGZ":       extract.FormatTarGzip,
+		"data.tar.zst":   extract.FormatTarZstd,
+		"data.tar.xz":    extract.FormatTarXz,
+		"data.tar.bz2":   extract.FormatTarBzip2,
+		"data.tar":       extract.FormatTar,
+		"dir/bundle.zip": extract.FormatZip,
+	}
+	for name, want := range tests {
+		if got, ok := extract.Detect(name); !ok || got != want {
+			t.Errorf("Detect(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+
+	for _, name := range []string{"data.gz", "model.safetensors", "tar"} {
+		if _, ok := extract.Detect(name); ok {
+			t.Errorf("expected %q not to be detected as an archive", name)
+		}
+	}
+}
+
+func TestExtract_Formats(t *testing.T) {
+	t.Parallel()
+
+	want := map[string]string{
+		"release-1.0/README.md":      "readme",
+		"release-1.0/bin/tool":       "binary",
+		"release-1.0/docs/guide.txt": "guide",
+	}
+
+	archives := map[extract.Format][]byte{
+		extract.FormatTar:     makeTar(t, sample),
+		extract.FormatTarGzip: compress(t, makeTar(t, sample), extract.FormatTarGzip),
+		extract.FormatTarXz:   compress(t, makeTar(t, sample), extract.FormatTarXz),
+		extract.FormatTarZstd: compress(t, makeTar(t, sample), extract.FormatTarZstd),
+		extract.FormatZip:     makeZip(t, sample),
+	}
+
+	for format, data := range archives {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := extract.Extract(bytes.NewReader(data), format, dir, types.ExtractOptions{}); err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			got := listFiles(t, dir)
+			if len(got) != len(want) {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+			for k, v := range want {
+				if got[k] != v {
+					t.Errorf("expected %q to contain %q, got %q", k, v, got[k])
+				}
+			}
+		})
+	}
+}
+
+func TestExtract_StripAndFilter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	err := extract.Extract(bytes.NewReader(makeTar(t, sample)), extract.FormatTar, dir, types.ExtractOptions{
+		StripComponents: 1,
+		Include:         []string{"**/*.md", "**/*.txt"},
+		Exclude:         []string{"docs/**"},
+	})
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	got := listFiles(t, dir)
+	var names []string
+	for k := range got {
+		names = append(names, k)
+	}
+	if !slices.Equal(names, []string{"README.md"}) {
+		t.Errorf("expected only README.md, got %v", names)
+	}
+}
+
+func TestExtract_RejectsEscapingEntries(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"../evil.txt", "a/../../evil.txt", "/etc/evil.txt"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "target")
+			entries := []entry{{name: name, content: "x"}}
+
+			for format, data := range map[extract.Format][]byte{
+				extract.FormatTar: makeTar(t, entries),
+				extract.FormatZip: makeZip(t, entries),
+			} {
+				err := extract.Extract(bytes.NewReader(data), format, dir, types.ExtractOptions{})
+				if err == nil || !strings.Contains(err.Error(), "outside the target directory") {
+					t.Errorf("%s: expected escaping entry to be rejected, got %v", format, err)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(parent, "evil.txt")); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected no file outside the target, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExtract_DoesNotFollowEscapingSymlinks(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "target")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(parent, filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	data := makeTar(t, []entry{{name: "escape/evil.txt", content: "x"}})
+	if err := extract.Extract(bytes.NewReader(data), extract.FormatTar, dir, types.ExtractOptions{}); err == nil {
+		t.Error("expected writing through an escaping symlink to fail")
+	}
+	if _, err := os.Stat(filepath.Join(parent, "evil.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no file outside the target, got %v", err)
+	}
+}
+
+func TestProcessor_StreamsArchives(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	archive := compress(t, makeTar(t, sample), extract.FormatTarGzip)
+
+	processor := extract.Processor(types.Source{Extract: &types.ExtractOptions{StripComponents: 1}}, func(ctx context.Context, j types.ObjectToDownload) error {
+		fh, err := extract.Create(ctx, j.Path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = fh.Close()
+		}()
+		_, err = io.Copy(fh, bytes.NewReader([]byte(j.ActualPath)))
+		return err
+	})
+
+	if err := processor(context.Background(), types.ObjectToDownload{ActualPath: string(archive), Path: filepath.Join(dir, "release.tar.gz")}); err != nil {
+		t.Fatalf("processor failed: %v", err)
+	}
+	if err := processor(context.Background(), types.ObjectToDownload{ActualPath: "plain", Path: filepath.Join(dir, "notes.txt")}); err != nil {
+		t.Fatalf("processor failed: %v", err)
+	}
+
+	got := listFiles(t, dir)
+	want := map[string]string{"README.md": "readme", "bin/tool": "binary", "docs/guide.txt": "guide", "notes.txt": "plain"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("expected %q to contain %q, got %q", k, v, got[k])
+		}
+	}
+}
+
+func TestProcessor_ReportsCorruptArchives(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	processor := extract.Processor(types.Source{Extract: &types.ExtractOptions{}}, func(ctx context.Context, j types.ObjectToDownload) error {
+		// Close errors are ignored here, as most fetchers only log them.
+		fh, err := extract.Create(ctx, j.Path)
+		if err != nil {
+			return err
+		}
+		_, _ = fh.Write([]byte("not a gzip stream"))
+		_ = fh.Close()
+		return nil
+	})
+
+	err := processor(context.Background(), types.ObjectToDownload{Path: filepath.Join(dir, "broken.tgz")})
+	if err == nil || !strings.Contains(err.Error(), "failed to extract") {
+		t.Errorf("expected extraction error, got %v", err)
+	}
+}
+
+func TestCreate_WithoutProcessor(t *testing.T) {
+	t.Parallel()
+
+	target := filepath.Join(t.TempDir(), "data.tar.gz")
+	if err := extract.WriteFile(context.Background(), target, []byte("raw"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if data, err := os.ReadFile(target); err != nil || string(data) != "raw" {
+		t.Errorf("expected archive to be written as-is, got %q (%v)", data, err)
+	}
+}
@@ -0,0 +1,213 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+var errNotClosed = errors.New("writer was not closed")
+
+type contextKey struct{}
+
+// tracker holds the extractions started while processing a single object.
+type tracker struct {
+	extract    *types.ExtractOptions
+	decompress bool
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	pipes      []*pipeWriter
+	errs       []error
+}
+
+type pipeWriter struct {
+	*io.PipeWriter
+	t         *tracker
+	done      chan struct{}
+	err       error
+	abandoned bool
+}
+
+// Processor wraps next so that files it writes through Create or OpenFile are extracted or decompressed as
+// configured on src instead of being written as-is. It returns once every extraction has finished, and fails
+// if an extraction of a writer that was closed with Close failed. Abandoned writers do not fail the object, so
+// that a retry or a mirror can still write it.
+func Processor(src types.Source, next func(context.Context, types.ObjectToDownload) error) func(context.Context, types.ObjectToDownload) error {
+	if src.Extract == nil && !src.Decompress {
+		return next
+	}
+
+	return func(ctx context.Context, j types.ObjectToDownload) error {
+		t := &tracker{extract: src.Extract, decompress: src.Decompress}
+		err := next(context.WithValue(ctx, contextKey{}, t), j)
+
+		t.mu.Lock()
+		pipes := t.pipes
+		t.mu.Unlock()
+		for _, pw := range pipes {
+			_ = pw.CloseWithError(errNotClosed)
+		}
+		t.wg.Wait()
+
+		if err != nil {
+			return err
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return errors.Join(t.errs...)
+	}
+}
+
+// Applies reports whether writing name through OpenFile would extract or decompress it.
+func Applies(ctx context.Context, name string) bool {
+	t, ok := ctx.Value(contextKey{}).(*tracker)
+	if !ok {
+		return false
+	}
+	if _, ok = Detect(name); ok && t.extract != nil {
+		return true
+	}
+	_, _, ok = DecompressedName(name)
+	return ok && t.decompress
+}
+
+func Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	return OpenFile(ctx, name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o666)
+}
+
+// OpenFile behaves like os.OpenFile, unless ctx comes from Processor. Supported archives are then streamed
+// into the directory of name, and compressed files are decoded into name without its compression suffix.
+// Close returns once the content has been written.
+func OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+	t, ok := ctx.Value(contextKey{}).(*tracker)
+	if !ok {
+		return os.OpenFile(name, flag, perm)
+	}
+
+	if format, isArchive := Detect(name); isArchive && t.extract != nil {
+		return t.start(name, func(r io.Reader) error {
+			return Extract(r, format, filepath.Dir(name), *t.extract)
+		}), nil
+	}
+
+	if target, ext, compressed := DecompressedName(name); compressed && t.decompress {
+		fh, err := os.OpenFile(target, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+		return t.start(name, func(r io.Reader) error {
+			return decompress(r, ext, fh)
+		}), nil
+	}
+
+	return os.OpenFile(name, flag, perm)
+}
+
+// WriteFile behaves like os.WriteFile, handling name under the same conditions as OpenFile.
+func WriteFile(ctx context.Context, name string, data []byte, perm os.FileMode) error {
+	fh, err := OpenFile(ctx, name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = fh.Write(data)
+	if cerr := fh.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// start runs consume on everything written to the returned writer.
+func (t *tracker) start(name string, consume func(io.Reader) error) *pipeWriter {
+	pr, pw := io.Pipe()
+	w := &pipeWriter{PipeWriter: pw, t: t, done: make(chan struct{})}
+
+	t.mu.Lock()
+	t.pipes = append(t.pipes, w)
+	t.mu.Unlock()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		err := consume(pr)
+		if err == nil {
+			// Archives may be followed by padding that the readers do not consume.
+			_, err = io.Copy(io.Discard, pr)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to extract %q: %w", name, err)
+		}
+		_ = pr.CloseWithError(err)
+
+		w.err = err
+		close(w.done)
+	}()
+
+	return w
+}
+
+// decompress decodes r into fh, removing fh again if the content cannot be decoded.
+func decompress(r io.Reader, ext string, fh *os.File) (err error) {
+	defer func() {
+		if cerr := fh.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(fh.Name())
+		}
+	}()
+
+	dr, err := NewReader(r, ext)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = dr.Close()
+	}()
+
+	_, err = io.Copy(fh, dr)
+	return err
+}
+
+// Close ends the content and returns once it has been extracted or decompressed, with the error of doing so.
+// It returns nil after CloseWithError.
+func (w *pipeWriter) Close() error {
+	if w.abandoned {
+		return nil
+	}
+	_ = w.PipeWriter.Close()
+	<-w.done
+
+	if w.err != nil {
+		w.t.mu.Lock()
+		w.t.errs = append(w.t.errs, w.err)
+		w.t.mu.Unlock()
+	}
+	return w.err
+}
+
+// CloseWithError abandons the content written so far, so that it is not extracted or decompressed as if it
+// were complete. A partially decompressed file is removed, files already extracted from an archive stay.
+func (w *pipeWriter) CloseWithError(err error) error {
+	if w.abandoned {
+		return nil
+	}
+	w.abandoned = true
+	_ = w.PipeWriter.CloseWithError(err)
+	<-w.done
+	return nil
+}
+
+// Abort calls CloseWithError on w if it was returned by Create or OpenFile for an extraction or decompression.
+// Other writers are left unchanged and still have to be closed.
+func Abort(w io.WriteCloser, err error) {
+	if pw, ok := w.(*pipeWriter); ok {
+		_ = pw.CloseWithError(err)
+	}
+}
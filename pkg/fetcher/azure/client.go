@@ -0,0 +1,140 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+type ObjectInfo struct {
+	Key  string
+	Size int64
+}
+
+type Client interface {
+	ListObjects(ctx context.Context, container, prefix string) ([]ObjectInfo, error)
+	GetObject(ctx context.Context, container, object string) (io.ReadCloser, error)
+}
+
+type ClientFactory func(ctx context.Context, opts types.AzureOptions) (Client, error)
+
+type azureClient struct {
+	client *azblob.Client
+}
+
+func NewClient(client *azblob.Client) Client {
+	return &azureClient{client: client}
+}
+
+func (a *azureClient) ListObjects(ctx context.Context, container, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	pager := a.client.NewListBlobsFlatPager(container, &azblob.ListBlobsFlatOptions{Prefix: &prefix})
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list error: %w", err)
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			if item.Name == nil {
+				continue
+			}
+			var size int64
+			if item.Properties != nil && item.Properties.ContentLength != nil {
+				size = *item.Properties.ContentLength
+			}
+			objects = append(objects, ObjectInfo{
+				Key:  *item.Name,
+				Size: size,
+			})
+		}
+	}
+	return objects, nil
+}
+
+func (a *azureClient) GetObject(ctx context.Context, container, object string) (io.ReadCloser, error) {
+	resp, err := a.client.DownloadStream(ctx, container, object, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// AzureClientFactory picks the credential from opts.Auth. Without an explicit mode, a SAS token or an
+// account key is used when set and the container is read anonymously otherwise.
+func AzureClientFactory(_ context.Context, opts types.AzureOptions) (Client, error) {
+	serviceURL, err := ServiceURL(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := opts.Auth
+	if mode == "" {
+		switch {
+		case opts.SASToken != "":
+			mode = types.AzureAuthSAS
+		case opts.AccountKey != "":
+			mode = types.AzureAuthSharedKey
+		default:
+			mode = types.AzureAuthAnonymous
+		}
+	}
+
+	var client *azblob.Client
+	switch mode {
+	case types.AzureAuthAnonymous:
+		client, err = azblob.NewClientWithNoCredential(serviceURL, nil)
+	case types.AzureAuthSAS:
+		sasToken := strings.TrimPrefix(utils.FromEnv(opts.SASToken), "?")
+		if sasToken == "" {
+			return nil, fmt.Errorf("azure: sasToken must be set for auth mode %q", mode)
+		}
+		client, err = azblob.NewClientWithNoCredential(serviceURL+"?"+sasToken, nil)
+	case types.AzureAuthSharedKey:
+		accountKey := utils.FromEnv(opts.AccountKey)
+		if opts.Account == "" || accountKey == "" {
+			return nil, fmt.Errorf("azure: account and accountKey must be set for auth mode %q", mode)
+		}
+		cred, credErr := azblob.NewSharedKeyCredential(opts.Account, accountKey)
+		if credErr != nil {
+			return nil, fmt.Errorf("azure: invalid shared key: %w", credErr)
+		}
+		client, err = azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+	case types.AzureAuthManagedIdentity:
+		var miOpts azidentity.ManagedIdentityCredentialOptions
+		if opts.ClientID != "" {
+			miOpts.ID = azidentity.ClientID(opts.ClientID)
+		}
+		cred, credErr := azidentity.NewManagedIdentityCredential(&miOpts)
+		if credErr != nil {
+			return nil, fmt.Errorf("azure: failed to create managed identity credential: %w", credErr)
+		}
+		client, err = azblob.NewClient(serviceURL, cred, nil)
+	default:
+		return nil, fmt.Errorf("azure: unsupported auth mode %q", mode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("azure: failed to create client: %w", err)
+	}
+
+	return NewClient(client), nil
+}
+
+// ServiceURL returns the blob service URL of the account, or the custom endpoint (e.g. Azurite's
+// http://127.0.0.1:10000/devstoreaccount1) when set.
+func ServiceURL(opts types.AzureOptions) (string, error) {
+	if opts.Endpoint != "" {
+		return strings.TrimSuffix(opts.Endpoint, "/") + "/", nil
+	}
+	if opts.Account == "" {
+		return "", fmt.Errorf("azure: account or endpoint must be set")
+	}
+	return fmt.Sprintf("https://%s.blob.core.windows.net/", opts.Account), nil
+}
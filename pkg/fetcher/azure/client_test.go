@@ -0,0 +1,149 @@
+package azure_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/azure"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+// newBlobServer serves the subset of the Blob REST API used by the client, path-style like Azurite.
+func newBlobServer(t *testing.T, account, container string, blobs map[string]string, authorize func(r *http.Request) bool) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		containerPath := "/" + account + "/" + container
+		if r.URL.Path == containerPath && r.URL.Query().Get("comp") == "list" {
+			prefix := r.URL.Query().Get("prefix")
+			var sb strings.Builder
+			sb.WriteString(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>`)
+			for name, content := range blobs {
+				if strings.HasPrefix(name, prefix) {
+					_, _ = fmt.Fprintf(&sb, `<Blob><Name>%s</Name><Properties><Content-Length>%d</Content-Length><BlobType>BlockBlob</BlobType></Properties></Blob>`, name, len(content))
+				}
+			}
+			sb.WriteString(`</Blobs><NextMarker/></EnumerationResults>`)
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = io.WriteString(w, sb.String())
+			return
+		}
+
+		content, ok := blobs[strings.TrimPrefix(r.URL.Path, containerPath+"/")]
+		if !ok {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		_, _ = io.WriteString(w, content)
+	}))
+}
+
+func TestAzureClientFactory_SharedKey(t *testing.T) {
+	const account = "devstoreaccount1"
+	blobs := map[string]string{"data/a.txt": "alpha", "data/sub/b.txt": "bravo", "other.txt": "other"}
+
+	server := newBlobServer(t, account, "container", blobs, func(r *http.Request) bool {
+		return strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey "+account+":")
+	})
+	defer server.Close()
+
+	client, err := azure.AzureClientFactory(context.Background(), types.AzureOptions{
+		Account:    account,
+		AccountKey: base64.StdEncoding.EncodeToString([]byte("secret-key")),
+		Endpoint:   server.URL + "/" + account,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	objects, err := client.ListObjects(context.Background(), "container", "data/")
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %+v", objects)
+	}
+
+	reader, err := client.GetObject(context.Background(), "container", "data/sub/b.txt")
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+	data, _ := io.ReadAll(reader)
+	if string(data) != "bravo" {
+		t.Errorf("unexpected content %q", data)
+	}
+}
+
+func TestAzureClientFactory_SAS(t *testing.T) {
+	const account = "devstoreaccount1"
+	blobs := map[string]string{"file.txt": "content"}
+
+	server := newBlobServer(t, account, "container", blobs, func(r *http.Request) bool {
+		return r.URL.Query().Get("sig") == "signature" && r.Header.Get("Authorization") == ""
+	})
+	defer server.Close()
+
+	client, err := azure.AzureClientFactory(context.Background(), types.AzureOptions{
+		SASToken: "?sv=2022-11-02&sp=rl&sig=signature",
+		Endpoint: server.URL + "/" + account,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	reader, err := client.GetObject(context.Background(), "container", "file.txt")
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+	data, _ := io.ReadAll(reader)
+	if string(data) != "content" {
+		t.Errorf("unexpected content %q", data)
+	}
+}
+
+func TestAzureClientFactory_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts types.AzureOptions
+	}{
+		{name: "no account or endpoint", opts: types.AzureOptions{}},
+		{name: "unsupported mode", opts: types.AzureOptions{Account: "a", Auth: "oauth"}},
+		{name: "sas without token", opts: types.AzureOptions{Account: "a", Auth: types.AzureAuthSAS}},
+		{name: "shared key without key", opts: types.AzureOptions{Account: "a", Auth: types.AzureAuthSharedKey}},
+		{name: "shared key not base64", opts: types.AzureOptions{Account: "a", AccountKey: "not base64!"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := azure.AzureClientFactory(context.Background(), tt.opts); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestServiceURL(t *testing.T) {
+	got, err := azure.ServiceURL(types.AzureOptions{Account: "acct"})
+	if err != nil || got != "https://acct.blob.core.windows.net/" {
+		t.Errorf("unexpected service URL %q (%v)", got, err)
+	}
+
+	got, err = azure.ServiceURL(types.AzureOptions{Account: "acct", Endpoint: "http://127.0.0.1:10000/devstoreaccount1"})
+	if err != nil || got != "http://127.0.0.1:10000/devstoreaccount1/" {
+		t.Errorf("unexpected service URL %q (%v)", got, err)
+	}
+}
@@ -0,0 +1,102 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+type Fetcher struct {
+	clientFactory ClientFactory
+	logger        *slog.Logger
+}
+
+func NewFetcher(clientFactory ClientFactory, logger *slog.Logger) fetcher.Fetcher {
+	return &Fetcher{
+		clientFactory: clientFactory,
+		logger:        logger,
+	}
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	client, err := f.clientFactory(ctx, *src.Azure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create azure client: %w", err)
+	}
+
+	var allObjects []types.ObjectToDownload
+	for _, p := range src.Azure.Paths {
+		objects, listErr := client.ListObjects(ctx, src.Azure.Container, p)
+		if listErr != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", listErr)
+		}
+		for _, object := range objects {
+			if strings.HasSuffix(object.Key, "/") {
+				continue
+			}
+			allObjects = append(allObjects, types.ObjectToDownload{ActualPath: object.Key, Path: p, Size: object.Size})
+		}
+	}
+
+	if len(allObjects) == 0 {
+		f.logger.Info("no files found", "container", src.Azure.Container, "paths", src.Azure.Paths)
+	}
+
+	allObjects, err = utils.SelectObjects(mountPath, allObjects, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, job types.ObjectToDownload) error {
+			return f.download(ctx, client, mountPath, src.Azure.Container, job)
+		},
+		Objects: allObjects,
+		Workers: src.Azure.Workers,
+	}, nil
+}
+
+func (f *Fetcher) download(ctx context.Context, client Client, mountPath, container string, file types.ObjectToDownload) error {
+	targetPath := utils.ResolveTargetPath(mountPath, file)
+	f.logger.Info("downloading file", "container", container, "key", file.ActualPath)
+
+	reader, err := client.GetObject(ctx, container, file.ActualPath)
+	if err != nil {
+		return fmt.Errorf("failed to get object %q: %w", file.ActualPath, err)
+	}
+	defer func() {
+		if err = reader.Close(); err != nil {
+			f.logger.Warn("error closing object reader", "key", file.ActualPath, "error", err)
+		}
+	}()
+
+	if err = os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
+	}
+
+	fh, err := extract.Create(ctx, targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", targetPath, err)
+	}
+	defer func() {
+		if err = fh.Close(); err != nil {
+			f.logger.Warn("error closing file", "file", targetPath, "error", err)
+		}
+	}()
+
+	if _, err = io.Copy(fh, reader); err != nil {
+		extract.Abort(fh, err)
+		return fmt.Errorf("failed to copy content to %q: %w", targetPath, err)
+	}
+
+	return nil
+}
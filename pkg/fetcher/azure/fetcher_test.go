@@ -0,0 +1,171 @@
+package azure_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/azure"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+type mockClient struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	listErr   error
+	getErr    error
+	listCalls []string
+	getCalls  []string
+}
+
+func (m *mockClient) ListObjects(_ context.Context, _, prefix string) ([]azure.ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listCalls = append(m.listCalls, prefix)
+
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+
+	var res []azure.ObjectInfo
+	for k, v := range m.objects {
+		if strings.HasPrefix(k, prefix) {
+			res = append(res, azure.ObjectInfo{Key: k, Size: int64(len(v))})
+		}
+	}
+	return res, nil
+}
+
+func (m *mockClient) GetObject(_ context.Context, _, object string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.getCalls = append(m.getCalls, object)
+
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+
+	data, ok := m.objects[object]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestFetcher_Fetch_Success(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mock := &mockClient{
+		objects: map[string][]byte{
+			"file1.txt":     []byte("hello"),
+			"dir/file2.txt": []byte("world"),
+			"dir/":          nil,
+		},
+	}
+	clientFactory := func(ctx context.Context, opts types.AzureOptions) (azure.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := azure.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	tmpDir := t.TempDir()
+	obj, err := fetcherInstance.Fetch(ctx, tmpDir, types.Source{
+		Type: types.SourceTypeAZURE,
+		Azure: &types.AzureOptions{
+			Container: "container",
+			Paths:     []string{"file1.txt", "dir/"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if len(obj.Objects) != 2 {
+		t.Fatalf("expected 2 objects to download, got %d", len(obj.Objects))
+	}
+
+	for _, o := range obj.Objects {
+		if pErr := obj.Processor(ctx, o); pErr != nil {
+			t.Fatalf("Processor failed for %q: %v", o.ActualPath, pErr)
+		}
+	}
+
+	for _, o := range obj.Objects {
+		targetPath := utils.ResolveTargetPath(tmpDir, o)
+		data, readErr := os.ReadFile(targetPath)
+		if readErr != nil {
+			t.Fatalf("failed to read downloaded file %q: %v", targetPath, readErr)
+		}
+		if want := mock.objects[o.ActualPath]; !bytes.Equal(data, want) {
+			t.Errorf("content mismatch for %q: got %q, want %q", o.ActualPath, data, want)
+		}
+	}
+}
+
+func TestFetcher_Fetch_FactoryError(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("factory failed")
+	clientFactory := func(ctx context.Context, opts types.AzureOptions) (azure.Client, error) {
+		return nil, wantErr
+	}
+
+	fetcherInstance := azure.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	_, err := fetcherInstance.Fetch(context.Background(), "/tmp", types.Source{
+		Type:  types.SourceTypeAZURE,
+		Azure: &types.AzureOptions{Container: "c", Paths: []string{"p"}},
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("expected factory error %v, got %v", wantErr, err)
+	}
+}
+
+func TestFetcher_Fetch_ListObjectsError(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockClient{listErr: errors.New("list error")}
+	clientFactory := func(ctx context.Context, opts types.AzureOptions) (azure.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := azure.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	_, err := fetcherInstance.Fetch(context.Background(), "/tmp", types.Source{
+		Type:  types.SourceTypeAZURE,
+		Azure: &types.AzureOptions{Container: "c", Paths: []string{"p"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to list objects") {
+		t.Errorf("expected list error, got %v", err)
+	}
+}
+
+func TestFetcher_Processor_GetObjectError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mock := &mockClient{
+		objects: map[string][]byte{"file.txt": []byte("data")},
+		getErr:  errors.New("get error"),
+	}
+	clientFactory := func(ctx context.Context, opts types.AzureOptions) (azure.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := azure.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	obj, err := fetcherInstance.Fetch(ctx, t.TempDir(), types.Source{
+		Type:  types.SourceTypeAZURE,
+		Azure: &types.AzureOptions{Container: "c", Paths: []string{"file.txt"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if pErr := obj.Processor(ctx, obj.Objects[0]); pErr == nil || !strings.Contains(pErr.Error(), "failed to get object") {
+		t.Errorf("expected get error, got %v", pErr)
+	}
+}
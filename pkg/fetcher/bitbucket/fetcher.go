@@ -0,0 +1,244 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/downloader"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+const (
+	entryTypeFile      = "commit_file"
+	entryTypeDirectory = "commit_directory"
+	pageLen            = 100
+)
+
+type Option func(*Fetcher)
+
+type Fetcher struct {
+	client     *http.Client
+	downloader downloader.Downloader
+	baseURL    string
+	logger     *slog.Logger
+}
+
+type SrcResponse struct {
+	Values []SrcEntry `json:"values"`
+	Next   string     `json:"next,omitempty"`
+}
+
+type SrcEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(h *Fetcher) {
+		h.client = client
+	}
+}
+
+func WithBaseURL(baseURL string) Option {
+	return func(f *Fetcher) {
+		f.baseURL = baseURL
+	}
+}
+
+func NewFetcher(downloader downloader.Downloader, logger *slog.Logger, opts ...Option) fetcher.Fetcher {
+	h := &Fetcher{
+		client:     http.DefaultClient,
+		downloader: downloader,
+		baseURL:    "https://api.bitbucket.org/2.0",
+		logger:     logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	var filesToDownload []types.ObjectToDownload
+	for _, p := range src.Bitbucket.Paths {
+		kind, err := utils.PathType(p)
+		if err != nil {
+			return nil, err
+		}
+		if kind == "" {
+			if kind, err = f.pathType(ctx, *src.Bitbucket, strings.Trim(p.Path, "/")); err != nil {
+				return nil, fmt.Errorf("looking up Bitbucket path %q: %w", p.Path, err)
+			}
+		}
+
+		if kind == types.PathTypeFile {
+			filesToDownload = append(filesToDownload, types.ObjectToDownload{
+				Path:       p.Path,
+				ActualPath: strings.TrimPrefix(p.Path, "/"),
+			})
+			continue
+		}
+
+		files, err := f.list(ctx, *src.Bitbucket, strings.Trim(p.Path, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("listing Bitbucket path %q: %w", p.Path, err)
+		}
+
+		for _, fl := range files {
+			filesToDownload = append(filesToDownload, types.ObjectToDownload{
+				Path:       p.Path,
+				ActualPath: fl,
+			})
+		}
+	}
+
+	filesToDownload, err := utils.SelectObjects(mountPath, filesToDownload, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
+			return f.download(ctx, mountPath, j, *src.Bitbucket)
+		},
+		Objects: filesToDownload,
+		Workers: src.Bitbucket.Workers,
+	}, nil
+}
+
+// list walks the directory at dir recursively, since the src endpoint only returns one level at a time.
+func (f *Fetcher) list(ctx context.Context, bbOpts types.BitbucketOptions, dir string) ([]string, error) {
+	nextURL := fmt.Sprintf("%s/?pagelen=%d", f.srcURL(bbOpts, dir), pageLen)
+
+	var files []string
+	for nextURL != "" {
+		page, err := f.listPage(ctx, bbOpts, nextURL)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range page.Values {
+			switch entry.Type {
+			case entryTypeFile:
+				files = append(files, entry.Path)
+			case entryTypeDirectory:
+				nested, nestedErr := f.list(ctx, bbOpts, strings.TrimSuffix(entry.Path, "/"))
+				if nestedErr != nil {
+					return nil, nestedErr
+				}
+				files = append(files, nested...)
+			}
+		}
+		nextURL = page.Next
+	}
+
+	return files, nil
+}
+
+// pathType looks p up, since the src endpoint serves file contents and directory listings alike.
+func (f *Fetcher) pathType(ctx context.Context, bbOpts types.BitbucketOptions, p string) (types.PathType, error) {
+	var entry SrcEntry
+	if err := f.get(ctx, bbOpts, f.srcURL(bbOpts, p)+"?format=meta", &entry); err != nil {
+		return "", err
+	}
+
+	switch entry.Type {
+	case entryTypeFile:
+		return types.PathTypeFile, nil
+	case entryTypeDirectory:
+		return types.PathTypeDir, nil
+	default:
+		return "", fmt.Errorf("unexpected entry type %q", entry.Type)
+	}
+}
+
+func (f *Fetcher) listPage(ctx context.Context, bbOpts types.BitbucketOptions, apiURL string) (*SrcResponse, error) {
+	var page SrcResponse
+	if err := f.get(ctx, bbOpts, apiURL, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (f *Fetcher) get(ctx context.Context, bbOpts types.BitbucketOptions, apiURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range authHeaders(bbOpts) {
+		req.Header.Add(k, v)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query Bitbucket API: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			f.logger.Warn("error closing response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Bitbucket API returned status %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+func (f *Fetcher) download(ctx context.Context, mountPath string, file types.ObjectToDownload, bbOpts types.BitbucketOptions) error {
+	f.logger.Info("downloading file", slog.String("project", fmt.Sprintf("%s/%s", bbOpts.Workspace, bbOpts.Repo)), slog.String("file", file.ActualPath))
+	return f.downloader.Download(ctx, f.srcURL(bbOpts, file.ActualPath), authHeaders(bbOpts), utils.ResolveTargetPath(mountPath, file))
+}
+
+// srcURL escapes the ref by segment, since the src endpoint accepts branch names such as feature/x as-is.
+func (f *Fetcher) srcURL(bbOpts types.BitbucketOptions, p string) string {
+	baseURL := f.baseURL
+	if bbOpts.Host != "" {
+		baseURL = strings.TrimSuffix(bbOpts.Host, "/")
+	}
+
+	srcURL := fmt.Sprintf("%s/repositories/%s/%s/src/%s",
+		baseURL,
+		url.PathEscape(bbOpts.Workspace),
+		url.PathEscape(bbOpts.Repo),
+		escapeSegments(bbOpts.Ref),
+	)
+	if p == "" {
+		return srcURL
+	}
+	return srcURL + "/" + escapeSegments(p)
+}
+
+func escapeSegments(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func authHeaders(bbOpts types.BitbucketOptions) map[string]string {
+	headers := map[string]string{}
+	switch {
+	case bbOpts.Token != "":
+		headers["Authorization"] = "Bearer " + utils.FromEnv(bbOpts.Token)
+	case bbOpts.Password != "":
+		credentials := utils.FromEnv(bbOpts.Username) + ":" + utils.FromEnv(bbOpts.Password)
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+	return headers
+}
@@ -0,0 +1,230 @@
+package bitbucket_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/bitbucket"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+type mockDownloader struct {
+	mu      sync.Mutex
+	urls    []string
+	dests   []string
+	headers map[string]string
+	err     error
+}
+
+func (m *mockDownloader) Download(_ context.Context, url string, headers map[string]string, dest string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.urls = append(m.urls, url)
+	m.dests = append(m.dests, dest)
+	m.headers = headers
+	return m.err
+}
+
+func TestFetcher_Fetch_Success(t *testing.T) {
+	t.Parallel()
+
+	var apiServer *httptest.Server
+	apiServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repositories/workspace/repo/src/main/docs/" && r.URL.Query().Get("page") == "":
+			_ = json.NewEncoder(w).Encode(bitbucket.SrcResponse{
+				Values: []bitbucket.SrcEntry{
+					{Path: "docs/index.md", Type: "commit_file"},
+				},
+				Next: apiServer.URL + "/repositories/workspace/repo/src/main/docs/?pagelen=100&page=2",
+			})
+		case r.URL.Path == "/repositories/workspace/repo/src/main/docs/":
+			_ = json.NewEncoder(w).Encode(bitbucket.SrcResponse{
+				Values: []bitbucket.SrcEntry{
+					{Path: "docs/guides", Type: "commit_directory"},
+				},
+			})
+		case r.URL.Path == "/repositories/workspace/repo/src/main/docs/guides/":
+			_ = json.NewEncoder(w).Encode(bitbucket.SrcResponse{
+				Values: []bitbucket.SrcEntry{
+					{Path: "docs/guides/setup.md", Type: "commit_file"},
+				},
+			})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.String())
+			http.NotFound(w, r)
+		}
+	}))
+	defer apiServer.Close()
+
+	md := &mockDownloader{}
+	fetcher := bitbucket.NewFetcher(md,
+		slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		bitbucket.WithHTTPClient(apiServer.Client()),
+		bitbucket.WithBaseURL(apiServer.URL),
+	)
+
+	src := types.Source{
+		Bitbucket: &types.BitbucketOptions{
+			Workspace: "workspace",
+			Repo:      "repo",
+			Ref:       "main",
+			Username:  "user",
+			Password:  "app-password",
+			Paths:     []types.RepoPath{{Path: "docs/"}},
+		},
+	}
+
+	destDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), destDir, src)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(obj.Objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(obj.Objects))
+	}
+
+	for _, o := range obj.Objects {
+		if err = obj.Processor(context.Background(), o); err != nil {
+			t.Fatalf("Processor failed: %v", err)
+		}
+	}
+
+	expectedURL := apiServer.URL + "/repositories/workspace/repo/src/main/docs/guides/setup.md"
+	if md.urls[1] != expectedURL {
+		t.Errorf("expected URL %s, got %s", expectedURL, md.urls[1])
+	}
+	if expectedDest := filepath.Join(destDir, "docs", "guides", "setup.md"); md.dests[1] != expectedDest {
+		t.Errorf("expected dest %s, got %s", expectedDest, md.dests[1])
+	}
+
+	expectedAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:app-password"))
+	if md.headers["Authorization"] != expectedAuth {
+		t.Errorf("expected basic auth header, got %v", md.headers)
+	}
+}
+
+func TestFetcher_Fetch_ListError(t *testing.T) {
+	t.Parallel()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer apiServer.Close()
+
+	fetcher := bitbucket.NewFetcher(&mockDownloader{},
+		slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		bitbucket.WithHTTPClient(apiServer.Client()),
+		bitbucket.WithBaseURL(apiServer.URL),
+	)
+
+	_, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{
+		Bitbucket: &types.BitbucketOptions{
+			Workspace: "workspace",
+			Repo:      "repo",
+			Ref:       "main",
+			Token:     "token",
+			Paths:     []types.RepoPath{{Path: "docs"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+func TestFetcher_Fetch_LooksUpPathType(t *testing.T) {
+	t.Parallel()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repositories/workspace/repo/src/main/Makefile" && r.URL.Query().Get("format") == "meta":
+			_ = json.NewEncoder(w).Encode(bitbucket.SrcEntry{Path: "Makefile", Type: "commit_file"})
+		case r.URL.Path == "/repositories/workspace/repo/src/main/configs.d" && r.URL.Query().Get("format") == "meta":
+			_ = json.NewEncoder(w).Encode(bitbucket.SrcEntry{Path: "configs.d", Type: "commit_directory"})
+		case r.URL.Path == "/repositories/workspace/repo/src/main/configs.d/":
+			_ = json.NewEncoder(w).Encode(bitbucket.SrcResponse{
+				Values: []bitbucket.SrcEntry{{Path: "configs.d/app.yaml", Type: "commit_file"}},
+			})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.String())
+			http.NotFound(w, r)
+		}
+	}))
+	defer apiServer.Close()
+
+	fetcher := bitbucket.NewFetcher(&mockDownloader{},
+		slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		bitbucket.WithHTTPClient(apiServer.Client()),
+		bitbucket.WithBaseURL(apiServer.URL),
+	)
+
+	obj, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{
+		Bitbucket: &types.BitbucketOptions{
+			Workspace: "workspace",
+			Repo:      "repo",
+			Ref:       "main",
+			Paths:     []types.RepoPath{{Path: "Makefile"}, {Path: "configs.d"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(obj.Objects) != 2 || obj.Objects[0].ActualPath != "Makefile" || obj.Objects[1].ActualPath != "configs.d/app.yaml" {
+		t.Fatalf("unexpected objects: %v", obj.Objects)
+	}
+}
+
+func TestFetcher_Fetch_HostAndSlashedRef(t *testing.T) {
+	t.Parallel()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/2.0/repositories/workspace/repo/src/feature/x/docs/" {
+			t.Errorf("unexpected request: %s", r.URL.String())
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(bitbucket.SrcResponse{
+			Values: []bitbucket.SrcEntry{{Path: "docs/index.md", Type: "commit_file"}},
+		})
+	}))
+	defer apiServer.Close()
+
+	md := &mockDownloader{}
+	fetcher := bitbucket.NewFetcher(md,
+		slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		bitbucket.WithHTTPClient(apiServer.Client()),
+	)
+
+	obj, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{
+		Bitbucket: &types.BitbucketOptions{
+			Host:      apiServer.URL + "/api/2.0/",
+			Workspace: "workspace",
+			Repo:      "repo",
+			Ref:       "feature/x",
+			Paths:     []types.RepoPath{{Path: "docs/"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if err = obj.Processor(context.Background(), obj.Objects[0]); err != nil {
+		t.Fatalf("Processor failed: %v", err)
+	}
+
+	if want := apiServer.URL + "/api/2.0/repositories/workspace/repo/src/feature/x/docs/index.md"; md.urls[0] != want {
+		t.Errorf("expected URL %s, got %s", want, md.urls[0])
+	}
+}
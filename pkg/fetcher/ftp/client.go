@@ -0,0 +1,121 @@
+package ftp
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	"github.com/jlaffaye/ftp"
+)
+
+const (
+	defaultPort       = 21
+	anonymousUser     = "anonymous"
+	anonymousPassword = "anonymous"
+)
+
+type ObjectInfo struct {
+	Key  string
+	Size int64
+}
+
+// Client is a single FTP control connection. FTP cannot run commands concurrently on one connection, so
+// a Client must only be used by one goroutine at a time and a reader from GetObject must be closed before
+// the next call.
+type Client interface {
+	ListObjects(root string) ([]ObjectInfo, error)
+	GetObject(path string) (io.ReadCloser, error)
+	Close() error
+}
+
+type ClientFactory func(ctx context.Context, opts types.FTPOptions) (Client, error)
+
+type ftpClient struct {
+	conn *ftp.ServerConn
+}
+
+func NewClient(addr, user, password string, dialOpts ...ftp.DialOption) (Client, error) {
+	conn, err := ftp.Dial(addr, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("ftp: failed to connect to %s: %w", addr, err)
+	}
+
+	if err = conn.Login(user, password); err != nil {
+		_ = conn.Quit()
+		return nil, fmt.Errorf("ftp: login as %q failed: %w", user, err)
+	}
+
+	return &ftpClient{conn: conn}, nil
+}
+
+// ListObjects walks root recursively. A root that is a file yields itself.
+func (c *ftpClient) ListObjects(root string) ([]ObjectInfo, error) {
+	if size, err := c.conn.FileSize(root); err == nil {
+		return []ObjectInfo{{Key: root, Size: size}}, nil
+	}
+
+	var objects []ObjectInfo
+	walker := c.conn.Walk(root)
+	for walker.Next() {
+		if entry := walker.Stat(); entry.Type == ftp.EntryTypeFile {
+			objects = append(objects, ObjectInfo{Key: walker.Path(), Size: int64(entry.Size)})
+		}
+	}
+	if err := walker.Err(); err != nil {
+		return nil, fmt.Errorf("list error: %w", err)
+	}
+	return objects, nil
+}
+
+func (c *ftpClient) GetObject(path string) (io.ReadCloser, error) {
+	return c.conn.Retr(path)
+}
+
+func (c *ftpClient) Close() error {
+	return c.conn.Quit()
+}
+
+func FTPClientFactory(ctx context.Context, opts types.FTPOptions) (Client, error) {
+	user, password := Credentials(opts)
+	return NewClient(Address(opts), user, password, DialOptions(ctx, opts)...)
+}
+
+func Address(opts types.FTPOptions) string {
+	port := opts.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(opts.Host, strconv.Itoa(port))
+}
+
+// Credentials returns the login for opts, falling back to an anonymous login when no user is set.
+func Credentials(opts types.FTPOptions) (string, string) {
+	if opts.User == "" {
+		return anonymousUser, anonymousPassword
+	}
+	return utils.FromEnv(opts.User), utils.FromEnv(opts.Password)
+}
+
+// DialOptions always uses passive mode. With TLS set the control connection is upgraded with AUTH TLS
+// (explicit FTPS) and data connections are protected as well.
+func DialOptions(ctx context.Context, opts types.FTPOptions) []ftp.DialOption {
+	dialOpts := []ftp.DialOption{
+		ftp.DialWithContext(ctx),
+		ftp.DialWithDisabledEPSV(opts.DisableEPSV),
+	}
+	if opts.TLS {
+		dialOpts = append(dialOpts, ftp.DialWithExplicitTLS(&tls.Config{
+			ServerName:         opts.Host,
+			InsecureSkipVerify: opts.InsecureSkipVerify,
+			MinVersion:         tls.VersionTLS12,
+			// servers commonly require data connections to resume the control connection session
+			ClientSessionCache: tls.NewLRUClientSessionCache(0),
+		}))
+	}
+	return dialOpts
+}
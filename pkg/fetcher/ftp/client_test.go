@@ -0,0 +1,370 @@
+package ftp_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ftpf "github.com/AdamShannag/volare/pkg/fetcher/ftp"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+// ftpServer is a minimal read-only FTP server serving root over passive data connections, with optional
+// explicit TLS. It understands just enough of the protocol for the client.
+type ftpServer struct {
+	root      string
+	users     map[string]string
+	tlsConfig *tls.Config
+	listener  net.Listener
+}
+
+func startServer(t *testing.T, root string, users map[string]string, withTLS bool) *ftpServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	s := &ftpServer{root: root, users: users, listener: listener}
+	if withTLS {
+		s.tlsConfig = selfSignedConfig(t)
+	}
+
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *ftpServer) options(t *testing.T) types.FTPOptions {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("invalid port: %v", err)
+	}
+	return types.FTPOptions{Host: host, Port: p}
+}
+
+type session struct {
+	server   *ftpServer
+	conn     net.Conn
+	rw       *bufio.ReadWriter
+	user     string
+	loggedIn bool
+	protect  bool
+	passive  net.Listener
+}
+
+func (s *ftpServer) serve(conn net.Conn) {
+	sess := &session{server: s, conn: conn, rw: bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))}
+	defer func() {
+		if sess.passive != nil {
+			_ = sess.passive.Close()
+		}
+		_ = sess.conn.Close()
+	}()
+
+	sess.reply("220 ready")
+	for {
+		line, err := sess.rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		if !sess.handle(strings.ToUpper(cmd), arg) {
+			return
+		}
+	}
+}
+
+func (sess *session) reply(lines ...string) {
+	for _, l := range lines {
+		_, _ = sess.rw.WriteString(l + "\r\n")
+	}
+	_ = sess.rw.Flush()
+}
+
+func (sess *session) handle(cmd, arg string) bool {
+	s := sess.server
+	switch cmd {
+	case "AUTH":
+		if s.tlsConfig == nil {
+			sess.reply("502 tls not supported")
+			return true
+		}
+		sess.reply("234 starting tls")
+		sess.conn = tls.Server(sess.conn, s.tlsConfig)
+		sess.rw = bufio.NewReadWriter(bufio.NewReader(sess.conn), bufio.NewWriter(sess.conn))
+	case "USER":
+		sess.user = arg
+		sess.reply("331 password required")
+	case "PASS":
+		if want, ok := s.users[sess.user]; !ok || want != arg {
+			sess.reply("530 login incorrect")
+			return true
+		}
+		sess.loggedIn = true
+		sess.reply("230 logged in")
+	case "FEAT":
+		sess.reply("211-Features:", " MLST type*;size*;", " EPSV", "211 End")
+	case "TYPE", "PBSZ":
+		sess.reply("200 ok")
+	case "PROT":
+		sess.protect = arg == "P"
+		sess.reply("200 ok")
+	case "EPSV", "PASV":
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			sess.reply("425 cannot open data connection")
+			return true
+		}
+		sess.passive = listener
+		port := listener.Addr().(*net.TCPAddr).Port
+		if cmd == "EPSV" {
+			sess.reply(fmt.Sprintf("229 Entering Extended Passive Mode (|||%d|)", port))
+		} else {
+			sess.reply(fmt.Sprintf("227 Entering Passive Mode (127,0,0,1,%d,%d)", port/256, port%256))
+		}
+	case "SIZE":
+		info, err := os.Stat(sess.path(arg))
+		if err != nil || !info.Mode().IsRegular() {
+			sess.reply("550 not a plain file")
+			return true
+		}
+		sess.reply(fmt.Sprintf("213 %d", info.Size()))
+	case "MLSD":
+		entries, err := os.ReadDir(sess.path(arg))
+		if err != nil {
+			sess.reply("550 no such directory")
+			return true
+		}
+		var listing strings.Builder
+		for _, e := range entries {
+			info, _ := e.Info()
+			kind := "file"
+			if e.IsDir() {
+				kind = "dir"
+			}
+			_, _ = fmt.Fprintf(&listing, "type=%s;size=%d; %s\r\n", kind, info.Size(), e.Name())
+		}
+		sess.transfer(strings.NewReader(listing.String()))
+	case "RETR":
+		fh, err := os.Open(sess.path(arg))
+		if err != nil {
+			sess.reply("550 no such file")
+			return true
+		}
+		defer func() { _ = fh.Close() }()
+		sess.transfer(fh)
+	case "QUIT":
+		sess.reply("221 bye")
+		return false
+	default:
+		sess.reply("502 not implemented")
+	}
+	return true
+}
+
+func (sess *session) path(p string) string {
+	return filepath.Join(sess.server.root, filepath.FromSlash(path.Clean("/"+p)))
+}
+
+func (sess *session) transfer(r io.Reader) {
+	if !sess.loggedIn || sess.passive == nil {
+		sess.reply("425 use EPSV first")
+		return
+	}
+	data, err := sess.passive.Accept()
+	_ = sess.passive.Close()
+	sess.passive = nil
+	if err != nil {
+		sess.reply("425 cannot open data connection")
+		return
+	}
+	if sess.protect {
+		data = tls.Server(data, sess.server.tlsConfig)
+	}
+
+	sess.reply("150 opening data connection")
+	_, _ = io.Copy(data, r)
+	_ = data.Close()
+	sess.reply("226 transfer complete")
+}
+
+func selfSignedConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+}
+
+func listAndRead(t *testing.T, client ftpf.Client, root, file string) ([]string, string) {
+	t.Helper()
+
+	objects, err := client.ListObjects(root)
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	var keys []string
+	for _, o := range objects {
+		keys = append(keys, o.Key)
+	}
+	slices.Sort(keys)
+
+	reader, err := client.GetObject(file)
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read %q: %v", file, err)
+	}
+	if err = reader.Close(); err != nil {
+		t.Fatalf("failed to close %q: %v", file, err)
+	}
+	return keys, string(data)
+}
+
+func TestClient_AnonymousRecursiveListing(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"pub/a.txt": "alpha", "pub/sub/b.txt": "bravo", "pub/sub/deep/c.txt": "charlie", "other.txt": "x"})
+	server := startServer(t, root, map[string]string{"anonymous": "anonymous"}, false)
+
+	opts := server.options(t)
+	client, err := ftpf.FTPClientFactory(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	keys, content := listAndRead(t, client, "/pub", "/pub/sub/deep/c.txt")
+	if want := []string{"/pub/a.txt", "/pub/sub/b.txt", "/pub/sub/deep/c.txt"}; !slices.Equal(keys, want) {
+		t.Errorf("expected %v, got %v", want, keys)
+	}
+	if content != "charlie" {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	objects, err := client.ListObjects("/other.txt")
+	if err != nil {
+		t.Fatalf("ListObjects on a file failed: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != "/other.txt" || objects[0].Size != 1 {
+		t.Errorf("expected the file itself, got %+v", objects)
+	}
+}
+
+func TestClient_CredentialsAndPASV(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"data/a.txt": "alpha"})
+	server := startServer(t, root, map[string]string{"volare": "secret"}, false)
+
+	t.Setenv("FTP_TEST_PASSWORD", "secret")
+	opts := server.options(t)
+	opts.User = "volare"
+	opts.Password = "FTP_TEST_PASSWORD"
+	opts.DisableEPSV = true
+
+	client, err := ftpf.FTPClientFactory(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	keys, content := listAndRead(t, client, "/data", "/data/a.txt")
+	if !slices.Equal(keys, []string{"/data/a.txt"}) || content != "alpha" {
+		t.Errorf("unexpected result %v %q", keys, content)
+	}
+
+	opts.Password = "wrong"
+	if _, err = ftpf.FTPClientFactory(context.Background(), opts); err == nil {
+		t.Error("expected login to fail")
+	}
+}
+
+func TestClient_ExplicitTLS(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"data/a.txt": "alpha", "data/b/c.txt": "charlie"})
+	server := startServer(t, root, map[string]string{"anonymous": "anonymous"}, true)
+
+	opts := server.options(t)
+	opts.TLS = true
+
+	if _, err := ftpf.FTPClientFactory(context.Background(), opts); err == nil {
+		t.Fatal("expected certificate verification to fail")
+	}
+
+	opts.InsecureSkipVerify = true
+	client, err := ftpf.FTPClientFactory(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	keys, content := listAndRead(t, client, "/data", "/data/b/c.txt")
+	if !slices.Equal(keys, []string{"/data/a.txt", "/data/b/c.txt"}) || content != "charlie" {
+		t.Errorf("unexpected result %v %q", keys, content)
+	}
+}
+
+func TestAddressAndCredentials(t *testing.T) {
+	if got := ftpf.Address(types.FTPOptions{Host: "ftp.example.com"}); got != "ftp.example.com:21" {
+		t.Errorf("expected default port, got %q", got)
+	}
+	if user, password := ftpf.Credentials(types.FTPOptions{}); user != "anonymous" || password != "anonymous" {
+		t.Errorf("expected anonymous login, got %q/%q", user, password)
+	}
+}
@@ -0,0 +1,199 @@
+package ftp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+type Fetcher struct {
+	clientFactory ClientFactory
+	logger        *slog.Logger
+}
+
+// pool hands out one connection per worker, dialing new ones on demand, since a single FTP connection
+// can only serve one transfer at a time.
+type pool struct {
+	mu     sync.Mutex
+	idle   []Client
+	all    []Client
+	dial   func() (Client, error)
+	closed bool
+}
+
+func NewFetcher(clientFactory ClientFactory, logger *slog.Logger) fetcher.Fetcher {
+	return &Fetcher{
+		clientFactory: clientFactory,
+		logger:        logger,
+	}
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	opts := *src.FTP
+
+	client, err := f.clientFactory(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ftp client: %w", err)
+	}
+
+	var allObjects []types.ObjectToDownload
+	for _, p := range opts.Paths {
+		objects, listErr := client.ListObjects(p)
+		if listErr != nil {
+			if cerr := client.Close(); cerr != nil {
+				f.logger.Warn("error closing ftp client", "error", cerr)
+			}
+			return nil, fmt.Errorf("failed to list %q: %w", p, listErr)
+		}
+		for _, object := range objects {
+			allObjects = append(allObjects, types.ObjectToDownload{ActualPath: object.Key, Path: p, Size: object.Size})
+		}
+	}
+
+	if len(allObjects) == 0 {
+		f.logger.Info("no files found", "host", opts.Host, "paths", opts.Paths)
+	}
+
+	clients := &pool{
+		idle: []Client{client},
+		all:  []Client{client},
+		dial: func() (Client, error) { return f.clientFactory(ctx, opts) },
+	}
+
+	allObjects, err = utils.SelectObjects(mountPath, allObjects, src)
+	if err != nil {
+		if cerr := clients.closeAll(); cerr != nil {
+			f.logger.Warn("error closing ftp client", "error", cerr)
+		}
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, job types.ObjectToDownload) error {
+			return f.download(ctx, clients, mountPath, opts.Host, job)
+		},
+		Objects: allObjects,
+		Workers: opts.Workers,
+		Cleanup: func(ctx context.Context) error {
+			f.logger.Info("closing ftp connections", "host", opts.Host)
+			return clients.closeAll()
+		},
+	}, nil
+}
+
+func (f *Fetcher) download(ctx context.Context, clients *pool, mountPath, host string, file types.ObjectToDownload) error {
+	client, err := clients.get()
+	if err != nil {
+		return fmt.Errorf("failed to create ftp client: %w", err)
+	}
+
+	if err = f.transfer(ctx, client, mountPath, host, file); err != nil {
+		// the connection may be left mid-transfer, so it is not reused
+		clients.discard(client)
+		return err
+	}
+
+	clients.put(client)
+	return nil
+}
+
+func (f *Fetcher) transfer(ctx context.Context, client Client, mountPath, host string, file types.ObjectToDownload) error {
+	targetPath := utils.ResolveTargetPath(mountPath, file)
+	f.logger.Info("downloading file", "host", host, "path", file.ActualPath)
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
+	}
+
+	reader, err := client.GetObject(file.ActualPath)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %q: %w", file.ActualPath, err)
+	}
+	defer func() {
+		if cerr := reader.Close(); cerr != nil {
+			f.logger.Warn("error closing remote file", "path", file.ActualPath, "error", cerr)
+		}
+	}()
+
+	fh, err := extract.Create(ctx, targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", targetPath, err)
+	}
+	defer func() {
+		if cerr := fh.Close(); cerr != nil {
+			f.logger.Warn("error closing file", "file", targetPath, "error", cerr)
+		}
+	}()
+
+	if _, err = io.Copy(fh, reader); err != nil {
+		extract.Abort(fh, err)
+		return fmt.Errorf("failed to copy content to %q: %w", targetPath, err)
+	}
+
+	return nil
+}
+
+func (p *pool) get() (Client, error) {
+	p.mu.Lock()
+	if n := len(p.idle); n > 0 {
+		client := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return client, nil
+	}
+	p.mu.Unlock()
+
+	client, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, errors.Join(errors.New("ftp connections are closed"), client.Close())
+	}
+	p.all = append(p.all, client)
+	return client, nil
+}
+
+func (p *pool) put(client Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.idle = append(p.idle, client)
+}
+
+func (p *pool) discard(client Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, c := range p.all {
+		if c == client {
+			p.all = append(p.all[:i], p.all[i+1:]...)
+			break
+		}
+	}
+	_ = client.Close()
+}
+
+func (p *pool) closeAll() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+
+	var errs []error
+	for _, c := range p.all {
+		errs = append(errs, c.Close())
+	}
+	p.all, p.idle = nil, nil
+	return errors.Join(errs...)
+}
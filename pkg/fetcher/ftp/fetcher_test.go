@@ -0,0 +1,230 @@
+package ftp_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	ftpf "github.com/AdamShannag/volare/pkg/fetcher/ftp"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+type mockClient struct {
+	objects map[string][]byte
+	listErr error
+	getErr  error
+	busy    atomic.Bool
+	shared  *atomic.Bool
+	closed  atomic.Bool
+}
+
+func (m *mockClient) ListObjects(root string) ([]ftpf.ObjectInfo, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+
+	var res []ftpf.ObjectInfo
+	for k, v := range m.objects {
+		if k == root || strings.HasPrefix(k, strings.TrimSuffix(root, "/")+"/") {
+			res = append(res, ftpf.ObjectInfo{Key: k, Size: int64(len(v))})
+		}
+	}
+	return res, nil
+}
+
+func (m *mockClient) GetObject(path string) (io.ReadCloser, error) {
+	if !m.busy.CompareAndSwap(false, true) {
+		m.shared.Store(true)
+	}
+	if m.getErr != nil {
+		m.busy.Store(false)
+		return nil, m.getErr
+	}
+	data, ok := m.objects[path]
+	if !ok {
+		m.busy.Store(false)
+		return nil, errors.New("550 file not found")
+	}
+	return &response{Reader: bytes.NewReader(data), client: m}, nil
+}
+
+func (m *mockClient) Close() error {
+	m.closed.Store(true)
+	return nil
+}
+
+type response struct {
+	*bytes.Reader
+	client *mockClient
+}
+
+func (r *response) Close() error {
+	r.client.busy.Store(false)
+	return nil
+}
+
+func TestFetcher_Fetch_ParallelConnections(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	objects := map[string][]byte{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		objects["/pub/"+name+".txt"] = []byte(name)
+	}
+
+	var (
+		mu      sync.Mutex
+		clients []*mockClient
+		shared  atomic.Bool
+	)
+	clientFactory := func(ctx context.Context, opts types.FTPOptions) (ftpf.Client, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		c := &mockClient{objects: objects, shared: &shared}
+		clients = append(clients, c)
+		return c, nil
+	}
+
+	workers := 4
+	fetcherInstance := ftpf.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	tmpDir := t.TempDir()
+	obj, err := fetcherInstance.Fetch(ctx, tmpDir, types.Source{
+		Type: types.SourceTypeFTP,
+		FTP:  &types.FTPOptions{Host: "ftp.example.com", Paths: []string{"/pub"}, Workers: &workers},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(obj.Objects) != len(objects) {
+		t.Fatalf("expected %d objects, got %d", len(objects), len(obj.Objects))
+	}
+
+	var wg sync.WaitGroup
+	jobs := make(chan types.ObjectToDownload)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if pErr := obj.Processor(ctx, j); pErr != nil {
+					t.Errorf("Processor failed for %q: %v", j.ActualPath, pErr)
+				}
+			}
+		}()
+	}
+	for _, o := range obj.Objects {
+		jobs <- o
+	}
+	close(jobs)
+	wg.Wait()
+
+	if shared.Load() {
+		t.Error("a connection was used by more than one transfer at a time")
+	}
+	for key, want := range objects {
+		data, readErr := os.ReadFile(filepath.Join(tmpDir, filepath.Base(key)))
+		if readErr != nil || !bytes.Equal(data, want) {
+			t.Errorf("unexpected content for %q: %q (%v)", key, data, readErr)
+		}
+	}
+
+	if err = obj.Cleanup(ctx); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if len(clients) > workers {
+		t.Errorf("expected at most %d connections, got %d", workers, len(clients))
+	}
+	for i, c := range clients {
+		if !c.closed.Load() {
+			t.Errorf("connection %d was not closed", i)
+		}
+	}
+}
+
+func TestFetcher_Fetch_FailedTransferDiscardsConnection(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	var shared atomic.Bool
+	var created []*mockClient
+	clientFactory := func(ctx context.Context, opts types.FTPOptions) (ftpf.Client, error) {
+		c := &mockClient{objects: map[string][]byte{"/pub/a.txt": []byte("a")}, shared: &shared}
+		created = append(created, c)
+		return c, nil
+	}
+
+	fetcherInstance := ftpf.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	tmpDir := t.TempDir()
+	obj, err := fetcherInstance.Fetch(ctx, tmpDir, types.Source{
+		Type: types.SourceTypeFTP,
+		FTP:  &types.FTPOptions{Host: "ftp.example.com", Paths: []string{"/pub"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if err = obj.Processor(ctx, types.ObjectToDownload{ActualPath: "/pub/missing.txt", Path: "/pub"}); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+	if !created[0].closed.Load() {
+		t.Error("expected the failed connection to be closed")
+	}
+
+	if err = obj.Processor(ctx, obj.Objects[0]); err != nil {
+		t.Fatalf("Processor failed: %v", err)
+	}
+	if len(created) != 2 {
+		t.Errorf("expected a new connection after a failed transfer, got %d connections", len(created))
+	}
+}
+
+func TestFetcher_Fetch_ListError(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockClient{listErr: errors.New("550 permission denied")}
+	clientFactory := func(ctx context.Context, opts types.FTPOptions) (ftpf.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := ftpf.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	_, err := fetcherInstance.Fetch(context.Background(), t.TempDir(), types.Source{
+		Type: types.SourceTypeFTP,
+		FTP:  &types.FTPOptions{Host: "h", Paths: []string{"/data"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to list") {
+		t.Errorf("expected list error, got %v", err)
+	}
+	if !mock.closed.Load() {
+		t.Error("expected client to be closed after a failed listing")
+	}
+}
+
+func TestFetcher_Fetch_InvalidPatternClosesConnection(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockClient{objects: map[string][]byte{"/pub/a.txt": []byte("a")}}
+	clientFactory := func(ctx context.Context, opts types.FTPOptions) (ftpf.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := ftpf.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	_, err := fetcherInstance.Fetch(context.Background(), t.TempDir(), types.Source{
+		Type:    types.SourceTypeFTP,
+		FTP:     &types.FTPOptions{Host: "h", Paths: []string{"/pub"}},
+		Include: []string{"[abc"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid glob pattern") {
+		t.Errorf("expected invalid pattern error, got %v", err)
+	}
+	if !mock.closed.Load() {
+		t.Error("expected client to be closed after an invalid pattern")
+	}
+}
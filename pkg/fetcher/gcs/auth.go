@@ -0,0 +1,90 @@
+package gcs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"cloud.google.com/go/storage"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+// ClientOptions translates the auth mode of opts into storage client options. Without an explicit
+// mode, a configured credentials file is used and anonymous access otherwise.
+func ClientOptions(ctx context.Context, opts types.GCSOptions, resourcesDir string) ([]option.ClientOption, error) {
+	var clientOpts []option.ClientOption
+	if opts.Endpoint != "" {
+		clientOpts = append(clientOpts, option.WithEndpoint(opts.Endpoint))
+	}
+
+	mode := opts.Auth
+	if mode == "" {
+		mode = types.GCSAuthAnonymous
+		if opts.CredentialsFile != "" {
+			mode = types.GCSAuthCredentialsFile
+		}
+	}
+
+	switch mode {
+	case types.GCSAuthAnonymous:
+		clientOpts = append(clientOpts, option.WithoutAuthentication())
+	case types.GCSAuthCredentialsFile, types.GCSAuthCredentialsJSON:
+		credsOpt, err := credentialsOption(opts, mode, resourcesDir)
+		if err != nil {
+			return nil, err
+		}
+		clientOpts = append(clientOpts, credsOpt)
+	case types.GCSAuthADC:
+	case types.GCSAuthWorkloadIdentity:
+		clientOpts = append(clientOpts, option.WithTokenSource(google.ComputeTokenSource("", storage.ScopeReadOnly)))
+	case types.GCSAuthImpersonate:
+		if opts.ImpersonateServiceAccount == "" {
+			return nil, fmt.Errorf("gcs: impersonateServiceAccount must be set for auth mode %q", mode)
+		}
+
+		var baseOpts []option.ClientOption
+		if opts.CredentialsFile != "" || opts.CredentialsJSON != "" {
+			baseMode := types.GCSAuthCredentialsJSON
+			if opts.CredentialsFile != "" {
+				baseMode = types.GCSAuthCredentialsFile
+			}
+			credsOpt, err := credentialsOption(opts, baseMode, resourcesDir)
+			if err != nil {
+				return nil, err
+			}
+			baseOpts = append(baseOpts, credsOpt)
+		}
+
+		ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+			TargetPrincipal: opts.ImpersonateServiceAccount,
+			Scopes:          []string{storage.ScopeReadOnly},
+		}, baseOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("gcs: failed to impersonate %q: %w", opts.ImpersonateServiceAccount, err)
+		}
+		clientOpts = append(clientOpts, option.WithTokenSource(ts))
+	default:
+		return nil, fmt.Errorf("gcs: unsupported auth mode %q", mode)
+	}
+
+	return clientOpts, nil
+}
+
+func credentialsOption(opts types.GCSOptions, mode types.GCSAuthMode, resourcesDir string) (option.ClientOption, error) {
+	if mode == types.GCSAuthCredentialsFile {
+		if opts.CredentialsFile == "" {
+			return nil, fmt.Errorf("gcs: credentialsFile must be set for auth mode %q", mode)
+		}
+		return option.WithCredentialsFile(filepath.Join(resourcesDir, opts.CredentialsFile)), nil
+	}
+
+	credentialsJSON := utils.FromEnv(opts.CredentialsJSON)
+	if credentialsJSON == "" {
+		return nil, fmt.Errorf("gcs: credentialsJson must be set for auth mode %q", mode)
+	}
+	return option.WithCredentialsJSON([]byte(credentialsJSON)), nil
+}
@@ -0,0 +1,134 @@
+package gcs_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/gcs"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+func TestClientOptions_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts types.GCSOptions
+	}{
+		{name: "unsupported mode", opts: types.GCSOptions{Auth: "kerberos"}},
+		{name: "credentials file missing", opts: types.GCSOptions{Auth: types.GCSAuthCredentialsFile}},
+		{name: "credentials json missing", opts: types.GCSOptions{Auth: types.GCSAuthCredentialsJSON}},
+		{name: "impersonate without target", opts: types.GCSOptions{Auth: types.GCSAuthImpersonate}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := gcs.ClientOptions(context.Background(), tt.opts, t.TempDir()); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestClientOptions_Modes(t *testing.T) {
+	tests := []struct {
+		name string
+		opts types.GCSOptions
+		want int
+	}{
+		{name: "default anonymous", opts: types.GCSOptions{}, want: 1},
+		{name: "default credentials file", opts: types.GCSOptions{CredentialsFile: "gcs/credentials.json"}, want: 1},
+		{name: "adc", opts: types.GCSOptions{Auth: types.GCSAuthADC}, want: 0},
+		{name: "workload identity", opts: types.GCSOptions{Auth: types.GCSAuthWorkloadIdentity}, want: 1},
+		{name: "credentials json", opts: types.GCSOptions{Auth: types.GCSAuthCredentialsJSON, CredentialsJSON: `{"type":"service_account"}`}, want: 1},
+		{name: "endpoint", opts: types.GCSOptions{Endpoint: "http://localhost:4443/storage/v1/"}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientOpts, err := gcs.ClientOptions(context.Background(), tt.opts, t.TempDir())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(clientOpts) != tt.want {
+				t.Errorf("expected %d client options, got %d", tt.want, len(clientOpts))
+			}
+		})
+	}
+}
+
+func TestGCSClientFactory_AnonymousEndpoint(t *testing.T) {
+	objects := map[string]string{
+		"data/a.txt":     "alpha",
+		"data/sub/b.txt": "bravo",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+
+		if r.URL.Path == "/storage/v1/b/bucket/o" {
+			prefix := r.URL.Query().Get("prefix")
+			var items []map[string]string
+			for name, content := range objects {
+				if strings.HasPrefix(name, prefix) {
+					items = append(items, map[string]string{
+						"name":   name,
+						"bucket": "bucket",
+						"size":   strconv.Itoa(len(content)),
+					})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"kind": "storage#objects", "items": items})
+			return
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, "/download/storage/v1/b/bucket/o/")
+		name = strings.TrimPrefix(name, "/bucket/")
+		content, ok := objects[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, content)
+	}))
+	defer server.Close()
+
+	f := gcs.NewFetcher(gcs.GCSClientFactory, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	mountPath := t.TempDir()
+	obj, err := f.Fetch(context.Background(), mountPath, types.Source{
+		GCS: &types.GCSOptions{
+			Bucket:   "bucket",
+			Paths:    []string{"data/"},
+			Auth:     types.GCSAuthAnonymous,
+			Endpoint: server.URL + "/storage/v1/",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(obj.Objects) != len(objects) {
+		t.Fatalf("expected %d objects, got %d", len(objects), len(obj.Objects))
+	}
+	for _, o := range obj.Objects {
+		if err = obj.Processor(context.Background(), o); err != nil {
+			t.Fatalf("processor failed for %q: %v", o.ActualPath, err)
+		}
+	}
+
+	got, err := os.ReadFile(filepath.Join(mountPath, "data/sub/b.txt"))
+	if err != nil {
+		t.Fatalf("failed to read downloaded file: %v", err)
+	}
+	if string(got) != "bravo" {
+		t.Errorf("unexpected content %q", got)
+	}
+}
@@ -5,17 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"path/filepath"
+	"net/http"
 
 	"cloud.google.com/go/storage"
 	"github.com/AdamShannag/volare/pkg/types"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
+var ErrObjectChanged = errors.New("gcs: object changed since listing")
+
 type ObjectInfo struct {
-	Key  string
-	Size int64
+	Key            string
+	Size           int64
+	Generation     int64
+	Metageneration int64
+}
+
+// Conditions pins a read to the generation and metageneration observed when listing.
+// Zero values are not enforced.
+type Conditions struct {
+	Generation     int64
+	Metageneration int64
 }
 
 type Options struct {
@@ -25,7 +37,8 @@ type Options struct {
 
 type Client interface {
 	ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error)
-	GetObject(ctx context.Context, bucket, object string) (io.ReadCloser, error)
+	GetObject(ctx context.Context, bucket, object string, cond Conditions) (io.ReadCloser, error)
+	GetObjectRange(ctx context.Context, bucket, object string, offset, length int64, cond Conditions) (io.ReadCloser, error)
 }
 
 type ClientFactory func(ctx context.Context, opts types.GCSOptions) (Client, error)
@@ -34,15 +47,8 @@ type gcsClient struct {
 	client *storage.Client
 }
 
-func NewClient(ctx context.Context, credentialsFile string) (Client, error) {
-	var opt option.ClientOption
-	if credentialsFile == "" {
-		opt = option.WithoutAuthentication()
-	} else {
-		opt = option.WithCredentialsFile(credentialsFile)
-	}
-
-	client, err := storage.NewClient(ctx, opt)
+func NewClient(ctx context.Context, clientOpts ...option.ClientOption) (Client, error) {
+	client, err := storage.NewClient(ctx, clientOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("gcs: failed to create client: %w", err)
 	}
@@ -67,17 +73,58 @@ func (g *gcsClient) ListObjects(ctx context.Context, bucket, prefix string) ([]O
 			continue
 		}
 		objects = append(objects, ObjectInfo{
-			Key:  attr.Name,
-			Size: attr.Size,
+			Key:            attr.Name,
+			Size:           attr.Size,
+			Generation:     attr.Generation,
+			Metageneration: attr.Metageneration,
 		})
 	}
 	return objects, nil
 }
 
-func (g *gcsClient) GetObject(ctx context.Context, bucket, object string) (io.ReadCloser, error) {
-	return g.client.Bucket(bucket).Object(object).NewReader(ctx)
+func (g *gcsClient) GetObject(ctx context.Context, bucket, object string, cond Conditions) (io.ReadCloser, error) {
+	reader, err := g.object(bucket, object, cond).NewReader(ctx)
+	if err != nil {
+		return nil, readError(object, cond, err)
+	}
+	return reader, nil
+}
+
+func (g *gcsClient) GetObjectRange(ctx context.Context, bucket, object string, offset, length int64, cond Conditions) (io.ReadCloser, error) {
+	reader, err := g.object(bucket, object, cond).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return nil, readError(object, cond, err)
+	}
+	return reader, nil
+}
+
+func (g *gcsClient) object(bucket, object string, cond Conditions) *storage.ObjectHandle {
+	handle := g.client.Bucket(bucket).Object(object)
+	if cond.Generation > 0 {
+		handle = handle.Generation(cond.Generation)
+	}
+	if cond.Metageneration > 0 {
+		handle = handle.If(storage.Conditions{MetagenerationMatch: cond.Metageneration})
+	}
+	return handle
+}
+
+// readError reports a failed precondition, or a pinned generation that no longer exists, as ErrObjectChanged.
+func readError(object string, cond Conditions, err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+		return fmt.Errorf("%w: %q", ErrObjectChanged, object)
+	}
+	if cond.Generation > 0 && errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("%w: %q generation %d no longer exists", ErrObjectChanged, object, cond.Generation)
+	}
+	return err
 }
 
 func GCSClientFactory(ctx context.Context, opts types.GCSOptions) (Client, error) {
-	return NewClient(ctx, filepath.Join(types.ResourcesDir, opts.CredentialsFile))
+	clientOpts, err := ClientOptions(ctx, opts, types.ResourcesDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(ctx, clientOpts...)
 }
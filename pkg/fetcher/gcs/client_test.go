@@ -0,0 +1,61 @@
+package gcs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/gcs"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+func TestGCSClient_GetObject_Conditions(t *testing.T) {
+	const generation = "1700000000000001"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/file.txt") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("generation") != generation {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("x-goog-if-metageneration-match") != "1" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		w.Header().Set("x-goog-generation", generation)
+		_, _ = io.WriteString(w, "data")
+	}))
+	defer server.Close()
+
+	client, err := gcs.GCSClientFactory(context.Background(), types.GCSOptions{Endpoint: server.URL + "/storage/v1/"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	reader, err := client.GetObject(context.Background(), "bucket", "file.txt", gcs.Conditions{Generation: 1700000000000001, Metageneration: 1})
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	_ = reader.Close()
+	if string(data) != "data" {
+		t.Errorf("unexpected content %q", data)
+	}
+
+	_, err = client.GetObject(context.Background(), "bucket", "file.txt", gcs.Conditions{Generation: 1700000000000001, Metageneration: 2})
+	if !errors.Is(err, gcs.ErrObjectChanged) {
+		t.Errorf("expected ErrObjectChanged on metageneration mismatch, got %v", err)
+	}
+
+	_, err = client.GetObject(context.Background(), "bucket", "file.txt", gcs.Conditions{Generation: 1700000000000002})
+	if !errors.Is(err, gcs.ErrObjectChanged) {
+		t.Errorf("expected ErrObjectChanged for a missing generation, got %v", err)
+	}
+}
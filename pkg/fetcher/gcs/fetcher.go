@@ -7,8 +7,11 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/AdamShannag/volare/pkg/downloader"
+	"github.com/AdamShannag/volare/pkg/extract"
 	"github.com/AdamShannag/volare/pkg/fetcher"
 	"github.com/AdamShannag/volare/pkg/types"
 	"github.com/AdamShannag/volare/pkg/utils"
@@ -29,20 +32,41 @@ func NewFetcher(clientFactory ClientFactory, logger *slog.Logger) fetcher.Fetche
 func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
 	client, err := f.clientFactory(ctx, *src.GCS)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+		return nil, fmt.Errorf("failed to create gcs client: %w", err)
 	}
 
 	var allObjects []types.ObjectToDownload
+	pinned := map[string]bool{}
 	for _, p := range src.GCS.Paths {
+		if key, generation, ok := parseGenerationPin(p); ok {
+			pinned[key] = true
+			allObjects = append(allObjects, types.ObjectToDownload{ActualPath: key, Path: key, Version: strconv.FormatInt(generation, 10)})
+		}
+	}
+
+	// Pinned keys are skipped when listing, so that the live object's metageneration is never applied to an
+	// older generation.
+	metagenerations := map[string]int64{}
+	for _, p := range src.GCS.Paths {
+		if _, _, ok := parseGenerationPin(p); ok {
+			continue
+		}
+
 		objects, listErr := client.ListObjects(ctx, src.GCS.Bucket, p)
 		if listErr != nil {
 			return nil, fmt.Errorf("failed to list objects: %w", listErr)
 		}
 		for _, object := range objects {
-			if strings.HasSuffix(object.Key, "/") {
+			if strings.HasSuffix(object.Key, "/") || pinned[object.Key] {
 				continue
 			}
-			allObjects = append(allObjects, types.ObjectToDownload{ActualPath: object.Key, Path: p})
+			metagenerations[object.Key] = object.Metageneration
+			allObjects = append(allObjects, types.ObjectToDownload{
+				ActualPath: object.Key,
+				Path:       p,
+				Version:    formatGeneration(object.Generation),
+				Size:       object.Size,
+			})
 		}
 	}
 
@@ -50,19 +74,42 @@ func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source)
 		f.logger.Info("no files found", "bucket", src.GCS.Bucket, "paths", src.GCS.Paths)
 	}
 
+	allObjects, err = utils.SelectObjects(mountPath, allObjects, src)
+	if err != nil {
+		return nil, err
+	}
+
 	return &fetcher.Object{
 		Processor: func(ctx context.Context, job types.ObjectToDownload) error {
-			return f.download(ctx, client, mountPath, src.GCS.Bucket, job)
+			cond := Conditions{Metageneration: metagenerations[job.ActualPath]}
+			if job.Version != "" {
+				generation, parseErr := strconv.ParseInt(job.Version, 10, 64)
+				if parseErr != nil {
+					return fmt.Errorf("invalid generation %q for %q: %w", job.Version, job.ActualPath, parseErr)
+				}
+				cond.Generation = generation
+			}
+			return f.download(ctx, client, mountPath, *src.GCS, job, cond)
 		},
 		Objects: allObjects,
 		Workers: src.GCS.Workers,
 	}, nil
 }
 
-func (f *Fetcher) download(ctx context.Context, client Client, mountPath, bucket string, file types.ObjectToDownload) error {
-	f.logger.Info("downloading file", "bucket", bucket, "key", file.ActualPath)
+func (f *Fetcher) download(ctx context.Context, client Client, mountPath string, opts types.GCSOptions, file types.ObjectToDownload, cond Conditions) error {
+	bucket := opts.Bucket
+	targetPath := utils.ResolveTargetPath(mountPath, file)
 
-	reader, err := client.GetObject(ctx, bucket, file.ActualPath)
+	if downloader.ShouldDownloadRanges(opts.Ranged, file.Size) && !extract.Applies(ctx, targetPath) {
+		f.logger.Info("downloading file in ranges", "bucket", bucket, "key", file.ActualPath, "size", file.Size)
+		return downloader.DownloadRanges(ctx, *opts.Ranged, file.Size, targetPath, func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			return client.GetObjectRange(ctx, bucket, file.ActualPath, offset, length, cond)
+		})
+	}
+
+	f.logger.Info("downloading file", "bucket", bucket, "key", file.ActualPath, "generation", cond.Generation)
+
+	reader, err := client.GetObject(ctx, bucket, file.ActualPath, cond)
 	if err != nil {
 		return fmt.Errorf("failed to get object %q: %w", file.ActualPath, err)
 	}
@@ -72,12 +119,11 @@ func (f *Fetcher) download(ctx context.Context, client Client, mountPath, bucket
 		}
 	}()
 
-	targetPath := utils.ResolveTargetPath(mountPath, file)
 	if err = os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
 		return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
 	}
 
-	fh, err := os.Create(targetPath)
+	fh, err := extract.Create(ctx, targetPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file %q: %w", targetPath, err)
 	}
@@ -88,8 +134,31 @@ func (f *Fetcher) download(ctx context.Context, client Client, mountPath, bucket
 	}()
 
 	if _, err = io.Copy(fh, reader); err != nil {
+		extract.Abort(fh, err)
 		return fmt.Errorf("failed to copy content to %q: %w", targetPath, err)
 	}
 
 	return nil
 }
+
+// parseGenerationPin splits "object#generation" paths. Object names may contain '#', so only a
+// positive numeric suffix is treated as a pin.
+func parseGenerationPin(p string) (string, int64, bool) {
+	i := strings.LastIndex(p, "#")
+	if i <= 0 {
+		return p, 0, false
+	}
+
+	generation, err := strconv.ParseInt(p[i+1:], 10, 64)
+	if err != nil || generation <= 0 {
+		return p, 0, false
+	}
+	return p[:i], generation, true
+}
+
+func formatGeneration(generation int64) string {
+	if generation == 0 {
+		return ""
+	}
+	return strconv.FormatInt(generation, 10)
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -17,14 +18,17 @@ import (
 )
 
 type mockClient struct {
-	mu         sync.Mutex
-	objects    map[string][]byte
-	listErr    error
-	getErr     error
-	listCalls  []string
-	getCalls   []string
-	failOnList bool
-	failOnGet  bool
+	mu          sync.Mutex
+	objects     map[string][]byte
+	generations map[string]int64
+	conds       []gcs.Conditions
+	listErr     error
+	getErr      error
+	listCalls   []string
+	getCalls    []string
+	rangeCalls  [][2]int64
+	failOnList  bool
+	failOnGet   bool
 }
 
 func (m *mockClient) ListObjects(_ context.Context, _, prefix string) ([]gcs.ObjectInfo, error) {
@@ -39,20 +43,24 @@ func (m *mockClient) ListObjects(_ context.Context, _, prefix string) ([]gcs.Obj
 	var res []gcs.ObjectInfo
 	for k, v := range m.objects {
 		if strings.HasPrefix(k, prefix) {
-			res = append(res, gcs.ObjectInfo{Key: k, Size: int64(len(v))})
+			res = append(res, gcs.ObjectInfo{Key: k, Size: int64(len(v)), Generation: m.generations[k], Metageneration: 1})
 		}
 	}
 	return res, nil
 }
 
-func (m *mockClient) GetObject(_ context.Context, _, object string) (io.ReadCloser, error) {
+func (m *mockClient) GetObject(_ context.Context, _, object string, cond gcs.Conditions) (io.ReadCloser, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.getCalls = append(m.getCalls, object)
+	m.conds = append(m.conds, cond)
 
 	if m.failOnGet {
 		return nil, m.getErr
 	}
+	if cond.Generation != 0 && cond.Generation != m.generations[object] {
+		return nil, gcs.ErrObjectChanged
+	}
 
 	data, ok := m.objects[object]
 	if !ok {
@@ -61,6 +69,23 @@ func (m *mockClient) GetObject(_ context.Context, _, object string) (io.ReadClos
 	return io.NopCloser(bytes.NewReader(data)), nil
 }
 
+func (m *mockClient) GetObjectRange(_ context.Context, _, object string, offset, length int64, cond gcs.Conditions) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rangeCalls = append(m.rangeCalls, [2]int64{offset, length})
+	m.conds = append(m.conds, cond)
+
+	if m.failOnGet {
+		return nil, m.getErr
+	}
+
+	data, ok := m.objects[object]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	return io.NopCloser(bytes.NewReader(data[offset : offset+length])), nil
+}
+
 func TestFetcher_Fetch_Success(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -183,3 +208,171 @@ func TestFetcher_Processor_GetObjectError(t *testing.T) {
 		t.Errorf("expected get error, got %v", pErr)
 	}
 }
+
+func TestFetcher_Processor_RangedDownload(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	content := []byte(strings.Repeat("0123456789", 10))
+	mock := &mockClient{
+		objects: map[string][]byte{
+			"large.bin": content,
+			"small.bin": []byte("small"),
+		},
+	}
+	clientFactory := func(ctx context.Context, opts types.GCSOptions) (gcs.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := gcs.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	tmpDir := t.TempDir()
+	obj, err := fetcherInstance.Fetch(ctx, tmpDir, types.Source{
+		Type: "gcs",
+		GCS: &types.GCSOptions{
+			Bucket: "b",
+			Paths:  []string{""},
+			Ranged: &types.RangedDownloadOptions{PartSize: 30, Threshold: 50},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	for _, o := range obj.Objects {
+		if pErr := obj.Processor(ctx, o); pErr != nil {
+			t.Fatalf("Processor failed for %q: %v", o.ActualPath, pErr)
+		}
+	}
+
+	data, err := os.ReadFile(utils.ResolveTargetPath(tmpDir, types.ObjectToDownload{ActualPath: "large.bin", Path: ""}))
+	if err != nil {
+		t.Fatalf("failed to read downloaded file: %v", err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("content mismatch: got %q, want %q", data, content)
+	}
+
+	if len(mock.rangeCalls) != 4 {
+		t.Errorf("expected 4 range requests, got %v", mock.rangeCalls)
+	}
+	if len(mock.getCalls) != 1 || mock.getCalls[0] != "small.bin" {
+		t.Errorf("expected small.bin to be read as a single stream, got %v", mock.getCalls)
+	}
+}
+
+func TestFetcher_Processor_GenerationConditions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mock := &mockClient{
+		objects:     map[string][]byte{"dir/file.txt": []byte("data")},
+		generations: map[string]int64{"dir/file.txt": 1700000000000001},
+	}
+	clientFactory := func(ctx context.Context, opts types.GCSOptions) (gcs.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := gcs.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	obj, err := fetcherInstance.Fetch(ctx, t.TempDir(), types.Source{
+		Type: "gcs",
+		GCS:  &types.GCSOptions{Bucket: "b", Paths: []string{"dir/"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(obj.Objects) != 1 || obj.Objects[0].Version != "1700000000000001" {
+		t.Fatalf("expected listed generation to be recorded, got %+v", obj.Objects)
+	}
+
+	if pErr := obj.Processor(ctx, obj.Objects[0]); pErr != nil {
+		t.Fatalf("Processor failed: %v", pErr)
+	}
+	want := gcs.Conditions{Generation: 1700000000000001, Metageneration: 1}
+	if len(mock.conds) != 1 || mock.conds[0] != want {
+		t.Errorf("expected read conditions %+v, got %+v", want, mock.conds)
+	}
+
+	mock.generations["dir/file.txt"] = 1700000000000002
+	if pErr := obj.Processor(ctx, obj.Objects[0]); !errors.Is(pErr, gcs.ErrObjectChanged) {
+		t.Errorf("expected ErrObjectChanged, got %v", pErr)
+	}
+}
+
+func TestFetcher_Fetch_GenerationPin(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mock := &mockClient{
+		objects:     map[string][]byte{"dir/file.txt": []byte("data"), "dir/notes#draft": []byte("notes")},
+		generations: map[string]int64{"dir/file.txt": 42},
+	}
+	clientFactory := func(ctx context.Context, opts types.GCSOptions) (gcs.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := gcs.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	tmpDir := t.TempDir()
+	obj, err := fetcherInstance.Fetch(ctx, tmpDir, types.Source{
+		Type: "gcs",
+		GCS:  &types.GCSOptions{Bucket: "b", Paths: []string{"dir/file.txt#42", "dir/notes#draft"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(mock.listCalls) != 1 || mock.listCalls[0] != "dir/notes#draft" {
+		t.Errorf("expected only the unpinned path to be listed, got %v", mock.listCalls)
+	}
+
+	for _, o := range obj.Objects {
+		if pErr := obj.Processor(ctx, o); pErr != nil {
+			t.Fatalf("Processor failed for %q: %v", o.ActualPath, pErr)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "file.txt"))
+	if err != nil {
+		t.Fatalf("failed to read pinned file: %v", err)
+	}
+	if string(data) != "data" {
+		t.Errorf("unexpected content %q", data)
+	}
+	if mock.conds[0].Generation != 42 {
+		t.Errorf("expected generation 42 to be pinned, got %+v", mock.conds[0])
+	}
+}
+
+func TestFetcher_Fetch_GenerationPinUnderListedPrefix(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mock := &mockClient{
+		objects:     map[string][]byte{"dir/file.txt": []byte("data"), "dir/other.txt": []byte("other")},
+		generations: map[string]int64{"dir/file.txt": 7, "dir/other.txt": 8},
+	}
+	clientFactory := func(ctx context.Context, opts types.GCSOptions) (gcs.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := gcs.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	obj, err := fetcherInstance.Fetch(ctx, t.TempDir(), types.Source{
+		Type: "gcs",
+		GCS:  &types.GCSOptions{Bucket: "b", Paths: []string{"dir/", "dir/file.txt#7"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(obj.Objects) != 2 {
+		t.Fatalf("expected the pinned key to be downloaded once, got %+v", obj.Objects)
+	}
+
+	for _, o := range obj.Objects {
+		if pErr := obj.Processor(ctx, o); pErr != nil {
+			t.Fatalf("Processor failed for %q: %v", o.ActualPath, pErr)
+		}
+	}
+	for i, key := range mock.getCalls {
+		if key == "dir/file.txt" && mock.conds[i] != (gcs.Conditions{Generation: 7}) {
+			t.Errorf("expected only the pinned generation for %q, got %+v", key, mock.conds[i])
+		}
+	}
+}
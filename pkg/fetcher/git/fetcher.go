@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 
 	"github.com/AdamShannag/volare/pkg/cloner"
+	"github.com/AdamShannag/volare/pkg/extract"
 	"github.com/AdamShannag/volare/pkg/fetcher"
 	"github.com/AdamShannag/volare/pkg/types"
 	"github.com/AdamShannag/volare/pkg/utils"
@@ -55,9 +56,17 @@ func (f *Fetcher) Fetch(_ context.Context, mountPath string, src types.Source) (
 		return nil, err
 	}
 
+	jobs, err = utils.SelectObjectsBy(mountPath, jobs, src, targetPath)
+	if err != nil {
+		if rmErr := os.RemoveAll(tempDir); rmErr != nil {
+			f.logger.Warn("error removing temp dir", "dir", tempDir, "error", rmErr)
+		}
+		return nil, err
+	}
+
 	return &fetcher.Object{
 		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
-			if copyErr := f.copy(j.Path, j.ActualPath); copyErr != nil {
+			if copyErr := f.copy(ctx, j.Path, j.ActualPath); copyErr != nil {
 				return copyErr
 			}
 
@@ -72,7 +81,7 @@ func (f *Fetcher) Fetch(_ context.Context, mountPath string, src types.Source) (
 	}, nil
 }
 
-func (f *Fetcher) copy(src, dest string) error {
+func (f *Fetcher) copy(ctx context.Context, src, dest string) error {
 	f.logger.Info("copying file", "dest", dest)
 	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
 		return fmt.Errorf("failed to create directory for %q: %w", dest, err)
@@ -89,7 +98,7 @@ func (f *Fetcher) copy(src, dest string) error {
 		}
 	}(inFile)
 
-	outFile, err := os.Create(dest)
+	outFile, err := extract.Create(ctx, dest)
 	if err != nil {
 		return fmt.Errorf("failed to create destination file %q: %w", dest, err)
 	}
@@ -100,6 +109,7 @@ func (f *Fetcher) copy(src, dest string) error {
 	}()
 
 	if _, err = io.Copy(outFile, inFile); err != nil {
+		extract.Abort(outFile, err)
 		return fmt.Errorf("failed to copy file to %q: %w", dest, err)
 	}
 	return nil
@@ -152,3 +162,7 @@ func (f *Fetcher) prepareJobs(tempDir, mountPath string, paths []string) ([]type
 
 	return jobs, nil
 }
+
+func targetPath(o *types.ObjectToDownload) *string {
+	return &o.ActualPath
+}
@@ -135,3 +135,40 @@ func TestFetcher_Fetch_PrepareJobsError(t *testing.T) {
 		t.Errorf("expected prepareJobs error, got nil")
 	}
 }
+
+func TestFetcher_Fetch_IncludeExclude(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockCloner{
+		createFiles: func(baseDir string) error {
+			for _, name := range []string{"src/main.go", "src/README.md", "src/tests/main_test.go"} {
+				p := filepath.Join(baseDir, name)
+				if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+					return err
+				}
+				if err := os.WriteFile(p, []byte(name), 0o644); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	f := git.NewFetcher(&mockFactory{cloner: mock}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	destDir := t.TempDir()
+	obj, err := f.Fetch(context.Background(), destDir, types.Source{
+		Git:     &types.GitOptions{Url: "https://example.com/repo.git", Paths: []string{"src"}},
+		Exclude: []string{"*.md", "tests/**"},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	defer func() {
+		_ = obj.Cleanup(context.Background())
+	}()
+
+	if len(obj.Objects) != 1 || obj.Objects[0].ActualPath != filepath.Join(destDir, "main.go") {
+		t.Errorf("expected only main.go, got %v", obj.Objects)
+	}
+}
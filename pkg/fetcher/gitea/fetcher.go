@@ -0,0 +1,189 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/downloader"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+const treePageSize = 1000
+
+type Option func(*Fetcher)
+
+type Fetcher struct {
+	client     *http.Client
+	downloader downloader.Downloader
+	logger     *slog.Logger
+}
+
+type TreeResponse struct {
+	Tree       []TreeEntry `json:"tree"`
+	Truncated  bool        `json:"truncated"`
+	Page       int         `json:"page"`
+	TotalCount int         `json:"total_count"`
+}
+
+type TreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(h *Fetcher) {
+		h.client = client
+	}
+}
+
+func NewFetcher(downloader downloader.Downloader, logger *slog.Logger, opts ...Option) fetcher.Fetcher {
+	h := &Fetcher{
+		client:     http.DefaultClient,
+		downloader: downloader,
+		logger:     logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	var tree []TreeEntry
+	var filesToDownload []types.ObjectToDownload
+	for _, p := range src.Gitea.Paths {
+		kind, err := utils.PathType(p)
+		if err != nil {
+			return nil, err
+		}
+		if kind == types.PathTypeFile {
+			filesToDownload = append(filesToDownload, types.ObjectToDownload{
+				Path:       p.Path,
+				ActualPath: strings.TrimPrefix(p.Path, "/"),
+			})
+			continue
+		}
+
+		if tree == nil {
+			if tree, err = f.list(ctx, *src.Gitea); err != nil {
+				return nil, fmt.Errorf("listing Gitea repository tree: %w", err)
+			}
+		}
+
+		found := false
+		for _, entry := range tree {
+			if entry.Type == "blob" && utils.InRepoPath(entry.Path, p.Path, kind) {
+				found = true
+				filesToDownload = append(filesToDownload, types.ObjectToDownload{
+					Path:       p.Path,
+					ActualPath: entry.Path,
+				})
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("path %q not found in %s/%s at %s", p.Path, src.Gitea.Owner, src.Gitea.Repo, src.Gitea.Ref)
+		}
+	}
+
+	filesToDownload, err := utils.SelectObjects(mountPath, filesToDownload, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
+			return f.download(ctx, mountPath, j, *src.Gitea)
+		},
+		Objects: filesToDownload,
+		Workers: src.Gitea.Workers,
+	}, nil
+}
+
+func (f *Fetcher) list(ctx context.Context, giteaOpts types.GiteaOptions) ([]TreeEntry, error) {
+	var entries []TreeEntry
+	for page := 1; ; page++ {
+		apiURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/git/trees/%s?recursive=true&per_page=%d&page=%d",
+			strings.TrimSuffix(giteaOpts.Host, "/"),
+			url.PathEscape(giteaOpts.Owner),
+			url.PathEscape(giteaOpts.Repo),
+			url.PathEscape(giteaOpts.Ref),
+			treePageSize,
+			page,
+		)
+
+		tree, err := f.listPage(ctx, giteaOpts, apiURL)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, tree.Tree...)
+		if !tree.Truncated || len(tree.Tree) == 0 || len(entries) >= tree.TotalCount {
+			return entries, nil
+		}
+	}
+}
+
+func (f *Fetcher) listPage(ctx context.Context, giteaOpts types.GiteaOptions, apiURL string) (*TreeResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range authHeaders(giteaOpts) {
+		req.Header.Add(k, v)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Gitea tree: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			f.logger.Warn("error closing response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Gitea API returned status %d", resp.StatusCode)
+	}
+
+	var tree TreeResponse
+	if err = json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+		return nil, fmt.Errorf("failed to decode tree: %w", err)
+	}
+
+	return &tree, nil
+}
+
+func (f *Fetcher) download(ctx context.Context, mountPath string, file types.ObjectToDownload, giteaOpts types.GiteaOptions) error {
+	segments := strings.Split(file.ActualPath, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	rawURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/raw/%s?ref=%s",
+		strings.TrimSuffix(giteaOpts.Host, "/"),
+		url.PathEscape(giteaOpts.Owner),
+		url.PathEscape(giteaOpts.Repo),
+		strings.Join(segments, "/"),
+		url.QueryEscape(giteaOpts.Ref),
+	)
+
+	f.logger.Info("downloading file", slog.String("project", fmt.Sprintf("%s/%s", giteaOpts.Owner, giteaOpts.Repo)), slog.String("file", file.ActualPath))
+	return f.downloader.Download(ctx, rawURL, authHeaders(giteaOpts), utils.ResolveTargetPath(mountPath, file))
+}
+
+func authHeaders(giteaOpts types.GiteaOptions) map[string]string {
+	headers := map[string]string{}
+	if giteaOpts.Token != "" {
+		headers["Authorization"] = "token " + utils.FromEnv(giteaOpts.Token)
+	}
+	return headers
+}
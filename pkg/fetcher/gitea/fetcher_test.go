@@ -0,0 +1,143 @@
+package gitea_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/gitea"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+type mockDownloader struct {
+	mu      sync.Mutex
+	urls    []string
+	dests   []string
+	headers map[string]string
+	err     error
+}
+
+func (m *mockDownloader) Download(_ context.Context, url string, headers map[string]string, dest string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.urls = append(m.urls, url)
+	m.dests = append(m.dests, dest)
+	m.headers = headers
+	return m.err
+}
+
+func TestFetcher_Fetch_Success(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]gitea.TreeResponse{
+		"1": {
+			Tree: []gitea.TreeEntry{
+				{Path: "configs", Type: "tree"},
+				{Path: "configs/app.yaml", Type: "blob"},
+			},
+			Truncated:  true,
+			Page:       1,
+			TotalCount: 3,
+		},
+		"2": {
+			Tree: []gitea.TreeEntry{
+				{Path: "configs/nested/db.yaml", Type: "blob"},
+			},
+			Page:       2,
+			TotalCount: 3,
+		},
+	}
+
+	var listCalls int
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/git/trees/main" {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "token secret" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		listCalls++
+		_ = json.NewEncoder(w).Encode(pages[r.URL.Query().Get("page")])
+	}))
+	defer apiServer.Close()
+
+	md := &mockDownloader{}
+	fetcher := gitea.NewFetcher(md, slog.New(slog.NewTextHandler(os.Stdout, nil)), gitea.WithHTTPClient(apiServer.Client()))
+
+	src := types.Source{
+		Gitea: &types.GiteaOptions{
+			Host:  apiServer.URL,
+			Owner: "owner",
+			Repo:  "repo",
+			Ref:   "main",
+			Token: "secret",
+			Paths: []types.RepoPath{{Path: "configs"}, {Path: "README.md", Type: types.PathTypeFile}},
+		},
+	}
+
+	destDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), destDir, src)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if listCalls != 2 {
+		t.Errorf("expected 2 tree pages to be listed, got %d", listCalls)
+	}
+	if len(obj.Objects) != 3 {
+		t.Fatalf("expected 3 objects, got %d", len(obj.Objects))
+	}
+
+	for _, o := range obj.Objects {
+		if err = obj.Processor(context.Background(), o); err != nil {
+			t.Fatalf("Processor failed: %v", err)
+		}
+	}
+
+	expectedURL := apiServer.URL + "/api/v1/repos/owner/repo/raw/configs/nested/db.yaml?ref=main"
+	if md.urls[1] != expectedURL {
+		t.Errorf("expected URL %s, got %s", expectedURL, md.urls[1])
+	}
+	if expectedDest := filepath.Join(destDir, "nested", "db.yaml"); md.dests[1] != expectedDest {
+		t.Errorf("expected dest %s, got %s", expectedDest, md.dests[1])
+	}
+	if expectedDest := filepath.Join(destDir, "README.md"); md.dests[2] != expectedDest {
+		t.Errorf("expected dest %s, got %s", expectedDest, md.dests[2])
+	}
+	if md.headers["Authorization"] != "token secret" {
+		t.Errorf("expected token header, got %v", md.headers)
+	}
+}
+
+func TestFetcher_Fetch_ListError(t *testing.T) {
+	t.Parallel()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer apiServer.Close()
+
+	fetcher := gitea.NewFetcher(&mockDownloader{}, slog.New(slog.NewTextHandler(os.Stdout, nil)), gitea.WithHTTPClient(apiServer.Client()))
+
+	_, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{
+		Gitea: &types.GiteaOptions{
+			Host:  apiServer.URL,
+			Owner: "owner",
+			Repo:  "repo",
+			Ref:   "main",
+			Paths: []types.RepoPath{{Path: "configs"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
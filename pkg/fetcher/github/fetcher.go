@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -15,6 +16,8 @@ import (
 	"github.com/AdamShannag/volare/pkg/utils"
 )
 
+var errNotFound = errors.New("GitHub API returned status 404")
+
 type Option func(*Fetcher)
 
 type Fetcher struct {
@@ -51,7 +54,8 @@ func NewFetcher(downloader downloader.Downloader, logger *slog.Logger, opts ...O
 }
 
 type githubResponse struct {
-	Tree []githubItem `json:"tree"`
+	Tree      []githubItem `json:"tree"`
+	Truncated bool         `json:"truncated"`
 }
 
 type githubItem struct {
@@ -59,30 +63,61 @@ type githubItem struct {
 	Type string `json:"type"`
 }
 
+type githubContent struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
 func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	var tree []githubItem
+	var listed, truncated bool
 	var filesToDownload []types.ObjectToDownload
 	for _, p := range src.GitHub.Paths {
-		if utils.IsFile(p) {
+		kind, err := utils.PathType(p)
+		if err != nil {
+			return nil, err
+		}
+		if kind == types.PathTypeFile {
 			filesToDownload = append(filesToDownload, types.ObjectToDownload{
-				Path:       p,
-				ActualPath: strings.TrimPrefix(p, "/"),
+				Path:       p.Path,
+				ActualPath: strings.TrimPrefix(p.Path, "/"),
 			})
 			continue
 		}
 
-		files, err := f.list(ctx, *src.GitHub, p)
-		if err != nil {
-			return nil, err
+		if !listed {
+			if tree, truncated, err = f.list(ctx, *src.GitHub); err != nil {
+				return nil, err
+			}
+			listed = true
+		}
+
+		// A truncated tree may miss files of any path, so look each one up through the contents API instead.
+		items := tree
+		if truncated {
+			if items, err = f.contents(ctx, *src.GitHub, strings.Trim(p.Path, "/")); err != nil {
+				return nil, err
+			}
 		}
 
-		for _, fl := range files {
-			if fl.Type == "blob" {
+		found := false
+		for _, item := range items {
+			if utils.InRepoPath(item.Path, p.Path, kind) {
+				found = true
 				filesToDownload = append(filesToDownload, types.ObjectToDownload{
-					Path:       p,
-					ActualPath: fl.Path,
+					Path:       p.Path,
+					ActualPath: item.Path,
 				})
 			}
 		}
+		if !found {
+			return nil, fmt.Errorf("path %q not found in %s/%s at %s", p.Path, src.GitHub.Owner, src.GitHub.Repo, src.GitHub.Ref)
+		}
+	}
+
+	filesToDownload, err := utils.SelectObjects(mountPath, filesToDownload, src)
+	if err != nil {
+		return nil, err
 	}
 
 	return &fetcher.Object{
@@ -94,7 +129,9 @@ func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source)
 	}, nil
 }
 
-func (f *Fetcher) list(ctx context.Context, ghOpts types.GitHubOptions, path string) ([]githubItem, error) {
+// list returns every file of the repository, so that each path can be looked up without another request.
+// It also reports whether GitHub truncated the tree, in which case the files are incomplete.
+func (f *Fetcher) list(ctx context.Context, ghOpts types.GitHubOptions) ([]githubItem, bool, error) {
 	apiURL := fmt.Sprintf("%s/repos/%s/%s/git/trees/%s?recursive=1",
 		f.baseURL,
 		url.PathEscape(ghOpts.Owner),
@@ -102,9 +139,78 @@ func (f *Fetcher) list(ctx context.Context, ghOpts types.GitHubOptions, path str
 		url.PathEscape(ghOpts.Ref),
 	)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	var tree githubResponse
+	if err := f.get(ctx, ghOpts, apiURL, &tree); err != nil {
+		return nil, false, fmt.Errorf("failed to list GitHub tree: %w", err)
+	}
+
+	var files []githubItem
+	for _, item := range tree.Tree {
+		if item.Type == "blob" {
+			files = append(files, item)
+		}
+	}
+
+	return files, tree.Truncated, nil
+}
+
+// contents returns the files at or below p, walking directories one level at a time.
+// A path that does not exist returns no files.
+func (f *Fetcher) contents(ctx context.Context, ghOpts types.GitHubOptions, p string) ([]githubItem, error) {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s",
+		f.baseURL,
+		url.PathEscape(ghOpts.Owner),
+		url.PathEscape(ghOpts.Repo),
+		strings.Join(segments, "/"),
+		url.QueryEscape(ghOpts.Ref),
+	)
+
+	var raw json.RawMessage
+	err := f.get(ctx, ghOpts, apiURL, &raw)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GitHub contents of %q: %w", p, err)
+	}
+
+	// The contents API returns an array for a directory and a single object for anything else.
+	var entries []githubContent
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		err = json.Unmarshal(raw, &entries)
+	} else {
+		entries = make([]githubContent, 1)
+		err = json.Unmarshal(raw, &entries[0])
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to decode contents of %q: %w", p, err)
+	}
+
+	var files []githubItem
+	for _, entry := range entries {
+		switch entry.Type {
+		case "file":
+			files = append(files, githubItem{Path: entry.Path, Type: "blob"})
+		case "dir":
+			nested, dirErr := f.contents(ctx, ghOpts, entry.Path)
+			if dirErr != nil {
+				return nil, dirErr
+			}
+			files = append(files, nested...)
+		}
+	}
+	return files, nil
+}
+
+// get decodes the JSON response of apiURL into v.
+func (f *Fetcher) get(ctx context.Context, ghOpts types.GitHubOptions, apiURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 	if ghOpts.Token != "" {
 		req.Header.Add("Authorization", "Bearer "+utils.FromEnv(ghOpts.Token))
@@ -112,7 +218,7 @@ func (f *Fetcher) list(ctx context.Context, ghOpts types.GitHubOptions, path str
 
 	resp, err := f.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list GitHub tree: %w", err)
+		return err
 	}
 	defer func() {
 		if cerr := resp.Body.Close(); cerr != nil {
@@ -120,27 +226,17 @@ func (f *Fetcher) list(ctx context.Context, ghOpts types.GitHubOptions, path str
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
 	}
-
-	var tree githubResponse
-	if err = json.NewDecoder(resp.Body).Decode(&tree); err != nil {
-		return nil, fmt.Errorf("failed to decode tree: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
 	}
 
-	var filtered []githubItem
-	trimPrefix := strings.Trim(path, "/") + "/"
-	for _, item := range tree.Tree {
-		if item.Type != "blob" {
-			continue
-		}
-		if path == "" || strings.HasPrefix(item.Path, trimPrefix) || item.Path == path {
-			filtered = append(filtered, item)
-		}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	return filtered, nil
+	return nil
 }
 
 func (f *Fetcher) download(ctx context.Context, mountPath string, file types.ObjectToDownload, ghOpts types.GitHubOptions) error {
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -66,7 +67,7 @@ func TestFetcher_Fetch_Success(t *testing.T) {
 			Owner: "owner",
 			Repo:  "repo",
 			Ref:   "main",
-			Paths: []string{"example"},
+			Paths: []types.RepoPath{{Path: "example"}},
 		},
 	}
 
@@ -129,7 +130,7 @@ func TestFetcher_Fetch_DownloadError(t *testing.T) {
 			Owner: "owner",
 			Repo:  "repo",
 			Ref:   "main",
-			Paths: []string{""},
+			Paths: []types.RepoPath{{Path: ""}},
 		},
 	}
 
@@ -163,7 +164,7 @@ func TestFetcher_Fetch_ListFilesError(t *testing.T) {
 			Owner: "owner",
 			Repo:  "repo",
 			Ref:   "main",
-			Paths: []string{"path"},
+			Paths: []types.RepoPath{{Path: "path"}},
 		},
 	}
 
@@ -184,7 +185,7 @@ func TestFetcher_Fetch_FilePathDirectly(t *testing.T) {
 			Owner: "o",
 			Repo:  "r",
 			Ref:   "main",
-			Paths: []string{"file.txt"},
+			Paths: []types.RepoPath{{Path: "file.txt", Type: types.PathTypeFile}},
 		},
 	}
 
@@ -210,3 +211,127 @@ func TestFetcher_Fetch_FilePathDirectly(t *testing.T) {
 		t.Errorf("expected dest %s, got %s", expectedDest, md.lastDest)
 	}
 }
+
+func TestFetcher_Fetch_PathTypes(t *testing.T) {
+	t.Parallel()
+
+	treeResp := map[string]interface{}{
+		"tree": []map[string]string{
+			{"path": "Makefile", "type": "blob"},
+			{"path": "configs.d/app.yaml", "type": "blob"},
+			{"path": "configs.d", "type": "tree"},
+		},
+	}
+
+	var listCalls int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&listCalls, 1)
+		_ = json.NewEncoder(w).Encode(treeResp)
+	}))
+	defer apiServer.Close()
+
+	fetcher := github.NewFetcher(&mockDownloader{},
+		slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		github.WithHTTPClient(apiServer.Client()),
+		github.WithBaseURL(apiServer.URL),
+	)
+
+	destDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), destDir, types.Source{
+		GitHub: &types.GitHubOptions{
+			Owner: "owner",
+			Repo:  "repo",
+			Ref:   "main",
+			Paths: []types.RepoPath{{Path: "Makefile"}, {Path: "configs.d/"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if listCalls != 1 {
+		t.Errorf("expected the tree to be listed once, got %d", listCalls)
+	}
+	if len(obj.Objects) != 2 || obj.Objects[0].ActualPath != "Makefile" || obj.Objects[1].ActualPath != "configs.d/app.yaml" {
+		t.Fatalf("unexpected objects: %v", obj.Objects)
+	}
+
+	_, err = fetcher.Fetch(context.Background(), destDir, types.Source{
+		GitHub: &types.GitHubOptions{
+			Owner: "owner",
+			Repo:  "repo",
+			Ref:   "main",
+			Paths: []types.RepoPath{{Path: "Makefile", Type: types.PathTypeDir}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestFetcher_Fetch_TruncatedTree(t *testing.T) {
+	t.Parallel()
+
+	contents := map[string]interface{}{
+		"/repos/owner/repo/contents/Makefile": map[string]string{"path": "Makefile", "type": "file"},
+		"/repos/owner/repo/contents/configs.d": []map[string]string{
+			{"path": "configs.d/app.yaml", "type": "file"},
+			{"path": "configs.d/nested", "type": "dir"},
+		},
+		"/repos/owner/repo/contents/configs.d/nested": []map[string]string{
+			{"path": "configs.d/nested/db.yaml", "type": "file"},
+		},
+	}
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/git/trees/") {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"tree":      []map[string]string{{"path": "Makefile", "type": "blob"}},
+				"truncated": true,
+			})
+			return
+		}
+		if r.URL.Query().Get("ref") != "main" {
+			t.Errorf("unexpected ref %q", r.URL.Query().Get("ref"))
+		}
+		resp, ok := contents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer apiServer.Close()
+
+	fetcher := github.NewFetcher(&mockDownloader{},
+		slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		github.WithHTTPClient(apiServer.Client()),
+		github.WithBaseURL(apiServer.URL),
+	)
+
+	src := types.Source{
+		GitHub: &types.GitHubOptions{
+			Owner: "owner",
+			Repo:  "repo",
+			Ref:   "main",
+			Paths: []types.RepoPath{{Path: "Makefile"}, {Path: "configs.d/"}},
+		},
+	}
+	obj, err := fetcher.Fetch(context.Background(), t.TempDir(), src)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	var got []string
+	for _, o := range obj.Objects {
+		got = append(got, o.ActualPath)
+	}
+	want := []string{"Makefile", "configs.d/app.yaml", "configs.d/nested/db.yaml"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected objects %v, got %v", want, got)
+	}
+
+	src.GitHub.Paths = []types.RepoPath{{Path: "missing"}}
+	if _, err = fetcher.Fetch(context.Background(), t.TempDir(), src); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+const (
+	artifactsArchiveName = "artifacts.zip"
+	packagesPerPage      = 100
+)
+
+type Package struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type PackageFile struct {
+	ID       int    `json:"id"`
+	FileName string `json:"file_name"`
+}
+
+func (f *Fetcher) fetchArtifacts(mountPath string, src types.Source) (*fetcher.Object, error) {
+	gitlabOpts := *src.Gitlab
+	baseURL := fmt.Sprintf("%s/api/v4/projects/%s/jobs/artifacts/%s",
+		gitlabOpts.Host,
+		url.PathEscape(gitlabOpts.Project),
+		url.PathEscape(gitlabOpts.Ref),
+	)
+	job := url.QueryEscape(gitlabOpts.Artifacts.Job)
+
+	object := types.ObjectToDownload{
+		ActualPath: fmt.Sprintf("%s/download?job=%s", baseURL, job),
+		Path:       filepath.Join(mountPath, artifactsArchiveName),
+	}
+
+	if artifactPath := strings.Trim(gitlabOpts.Artifacts.Path, "/"); artifactPath != "" {
+		object = types.ObjectToDownload{
+			ActualPath: fmt.Sprintf("%s/raw/%s?job=%s", baseURL, escapeSegments(artifactPath), job),
+			Path:       filepath.Join(mountPath, path.Base(artifactPath)),
+		}
+	}
+
+	return f.urlObject(mountPath, src, []types.ObjectToDownload{object})
+}
+
+func (f *Fetcher) fetchPackage(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	gitlabOpts := *src.Gitlab
+	if err := utils.ValidatePatterns(gitlabOpts.Package.Files); err != nil {
+		return nil, err
+	}
+
+	pkg, err := f.findPackage(ctx, gitlabOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := listAll[PackageFile](ctx, f, gitlabOpts, fmt.Sprintf("%s/api/v4/projects/%s/packages/%d/package_files?per_page=%d",
+		gitlabOpts.Host,
+		url.PathEscape(gitlabOpts.Project),
+		pkg.ID,
+		packagesPerPage,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("listing files of package %q: %w", pkg.Name, err)
+	}
+
+	var filesToDownload []types.ObjectToDownload
+	seen := make(map[string]struct{}, len(files))
+	for _, fl := range files {
+		if _, ok := seen[fl.FileName]; ok {
+			continue
+		}
+		if !utils.MatchPatterns(fl.FileName, gitlabOpts.Package.Files, nil) {
+			continue
+		}
+		seen[fl.FileName] = struct{}{}
+
+		filesToDownload = append(filesToDownload, types.ObjectToDownload{
+			ActualPath: fmt.Sprintf("%s/api/v4/projects/%s/packages/generic/%s/%s/%s",
+				gitlabOpts.Host,
+				url.PathEscape(gitlabOpts.Project),
+				url.PathEscape(pkg.Name),
+				url.PathEscape(pkg.Version),
+				url.PathEscape(fl.FileName),
+			),
+			Path: filepath.Join(mountPath, fl.FileName),
+		})
+	}
+
+	if len(filesToDownload) == 0 {
+		f.logger.Info("no files found", "package", pkg.Name, "version", pkg.Version, "files", gitlabOpts.Package.Files)
+	}
+
+	return f.urlObject(mountPath, src, filesToDownload)
+}
+
+func (f *Fetcher) findPackage(ctx context.Context, gitlabOpts types.GitlabOptions) (Package, error) {
+	pkgOpts := gitlabOpts.Package
+	packages, err := listAll[Package](ctx, f, gitlabOpts, fmt.Sprintf("%s/api/v4/projects/%s/packages?package_type=generic&package_name=%s&package_version=%s&per_page=%d",
+		gitlabOpts.Host,
+		url.PathEscape(gitlabOpts.Project),
+		url.QueryEscape(pkgOpts.Name),
+		url.QueryEscape(pkgOpts.Version),
+		packagesPerPage,
+	))
+	if err != nil {
+		return Package{}, fmt.Errorf("listing GitLab packages: %w", err)
+	}
+
+	for _, pkg := range packages {
+		if pkg.Name == pkgOpts.Name && pkg.Version == pkgOpts.Version {
+			return pkg, nil
+		}
+	}
+
+	return Package{}, fmt.Errorf("generic package %s@%s not found in project %q", pkgOpts.Name, pkgOpts.Version, gitlabOpts.Project)
+}
+
+// urlObject downloads objects whose ActualPath is a URL and whose Path is the target path.
+func (f *Fetcher) urlObject(mountPath string, src types.Source, objects []types.ObjectToDownload) (*fetcher.Object, error) {
+	gitlabOpts := *src.Gitlab
+	headers := authHeaders(gitlabOpts)
+
+	targetPath := func(o *types.ObjectToDownload) *string {
+		return &o.Path
+	}
+	objects, err := utils.SelectObjectsBy(mountPath, objects, src, targetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
+			f.logger.Info("downloading file", slog.String("project", gitlabOpts.Project), slog.String("file", filepath.Base(j.Path)))
+			return f.downloader.Download(ctx, j.ActualPath, headers, j.Path)
+		},
+		Objects: objects,
+		Workers: gitlabOpts.Workers,
+	}, nil
+}
+
+// listAll follows GitLab's X-Next-Page header until every page of apiURL has been read.
+func listAll[T any](ctx context.Context, f *Fetcher, gitlabOpts types.GitlabOptions, apiURL string) ([]T, error) {
+	var all []T
+	for page := "1"; page != ""; {
+		var items []T
+		next, err := f.getJSON(ctx, gitlabOpts, fmt.Sprintf("%s&page=%s", apiURL, url.QueryEscape(page)), &items)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		page = next
+	}
+	return all, nil
+}
+
+func (f *Fetcher) getJSON(ctx context.Context, gitlabOpts types.GitlabOptions, apiURL string, out any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range authHeaders(gitlabOpts) {
+		req.Header.Add(k, v)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			f.logger.Warn("error closing response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp.Header.Get("X-Next-Page"), nil
+}
+
+func escapeSegments(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -3,6 +3,7 @@ package gitlab
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -17,6 +18,8 @@ import (
 
 const gitlabTokenHeader = "PRIVATE-TOKEN"
 
+var errTreeNotFound = errors.New("tree not found")
+
 type Option func(*Fetcher)
 
 type Fetcher struct {
@@ -52,29 +55,57 @@ func NewFetcher(downloader downloader.Downloader, logger *slog.Logger, opts ...O
 }
 
 func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	switch {
+	case src.Gitlab.Artifacts != nil && src.Gitlab.Package != nil:
+		return nil, fmt.Errorf("gitlab artifacts and package cannot both be set")
+	case src.Gitlab.Artifacts != nil:
+		return f.fetchArtifacts(mountPath, src)
+	case src.Gitlab.Package != nil:
+		return f.fetchPackage(ctx, mountPath, src)
+	}
+
 	var filesToDownload []types.ObjectToDownload
 	for _, p := range src.Gitlab.Paths {
-		if utils.IsFile(p) {
-			filesToDownload = append(filesToDownload, types.ObjectToDownload{
-				Path:       p,
-				ActualPath: strings.TrimPrefix(p, "/"),
-			})
-			continue
+		kind, err := utils.PathType(p)
+		if err != nil {
+			return nil, err
 		}
 
-		files, err := f.list(ctx, *src.Gitlab, p)
-		if err != nil {
-			return nil, fmt.Errorf("listing GitLab path %q: %w", p, err)
+		var files []File
+		if kind != types.PathTypeFile {
+			files, err = f.list(ctx, *src.Gitlab, p.Path)
+			if err != nil && (kind == types.PathTypeDir || !errors.Is(err, errTreeNotFound)) {
+				return nil, fmt.Errorf("listing GitLab path %q: %w", p.Path, err)
+			}
 		}
 
+		found := false
 		for _, fl := range files {
 			if fl.Type == "blob" {
+				found = true
 				filesToDownload = append(filesToDownload, types.ObjectToDownload{
-					Path:       p,
+					Path:       p.Path,
 					ActualPath: fl.Path,
 				})
 			}
 		}
+
+		switch {
+		case found:
+		case kind == types.PathTypeDir:
+			return nil, fmt.Errorf("path %q not found in %s at %s", p.Path, src.Gitlab.Project, src.Gitlab.Ref)
+		default:
+			// The tree of a file is empty, so a path with nothing below it is downloaded as a file.
+			filesToDownload = append(filesToDownload, types.ObjectToDownload{
+				Path:       p.Path,
+				ActualPath: strings.TrimPrefix(p.Path, "/"),
+			})
+		}
+	}
+
+	filesToDownload, err := utils.SelectObjects(mountPath, filesToDownload, src)
+	if err != nil {
+		return nil, err
 	}
 
 	return &fetcher.Object{
@@ -112,6 +143,9 @@ func (f *Fetcher) list(ctx context.Context, gitlabOpts types.GitlabOptions, path
 		}
 	}()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errTreeNotFound
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("failed to list tree: status %d", resp.StatusCode)
 	}
@@ -132,11 +166,14 @@ func (f *Fetcher) download(ctx context.Context, mountPath string, file types.Obj
 		url.QueryEscape(src.Ref),
 	)
 
+	f.logger.Info("downloading file", slog.String("project", src.Project), slog.String("file", file.ActualPath))
+	return f.downloader.Download(ctx, fileURL, authHeaders(src), utils.ResolveTargetPath(mountPath, file))
+}
+
+func authHeaders(gitlabOpts types.GitlabOptions) map[string]string {
 	headers := map[string]string{}
-	if src.Token != "" {
-		headers[gitlabTokenHeader] = utils.FromEnv(src.Token)
+	if gitlabOpts.Token != "" {
+		headers[gitlabTokenHeader] = utils.FromEnv(gitlabOpts.Token)
 	}
-
-	f.logger.Info("downloading file", slog.String("project", src.Project), slog.String("file", file.ActualPath))
-	return f.downloader.Download(ctx, fileURL, headers, utils.ResolveTargetPath(mountPath, file))
+	return headers
 }
@@ -59,7 +59,7 @@ func TestFetcher_Fetch_Success(t *testing.T) {
 			Host:    apiServer.URL,
 			Project: "project",
 			Ref:     "main",
-			Paths:   []string{"path"},
+			Paths:   []types.RepoPath{{Path: "path"}},
 		},
 	}
 
@@ -99,7 +99,7 @@ func TestFetcher_Fetch_DirectFile(t *testing.T) {
 			Host:    "https://gitlab.com",
 			Project: "proj",
 			Ref:     "main",
-			Paths:   []string{"myfile.txt"},
+			Paths:   []types.RepoPath{{Path: "myfile.txt", Type: types.PathTypeFile}},
 		},
 	}
 
@@ -138,7 +138,7 @@ func TestFetcher_Fetch_ListFilesError(t *testing.T) {
 			Host:    apiServer.URL,
 			Project: "project",
 			Ref:     "main",
-			Paths:   []string{"path"},
+			Paths:   []types.RepoPath{{Path: "path"}},
 		},
 	}
 
@@ -167,7 +167,7 @@ func TestFetcher_Fetch_InvalidJSON(t *testing.T) {
 			Host:    apiServer.URL,
 			Project: "project",
 			Ref:     "main",
-			Paths:   []string{"path"},
+			Paths:   []types.RepoPath{{Path: "path"}},
 		},
 	}
 
@@ -198,7 +198,7 @@ func TestFetcher_Processor_DownloadError(t *testing.T) {
 			Host:    apiServer.URL,
 			Project: "project",
 			Ref:     "main",
-			Paths:   []string{""},
+			Paths:   []types.RepoPath{{Path: ""}},
 		},
 	}
 
@@ -211,3 +211,271 @@ func TestFetcher_Processor_DownloadError(t *testing.T) {
 		t.Fatalf("expected mock download error, got %v", err)
 	}
 }
+
+func TestFetcher_Fetch_ArtifactsPath(t *testing.T) {
+	t.Parallel()
+
+	md := &mockDownloader{}
+	fetcher := gitlab.NewFetcher(md, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	src := types.Source{
+		Gitlab: &types.GitlabOptions{
+			Host:    "https://gitlab.example.com",
+			Project: "group/project",
+			Ref:     "main",
+			Token:   "secret",
+			Artifacts: &types.GitlabArtifactsOptions{
+				Job:  "build linux",
+				Path: "dist/app.tar.gz",
+			},
+		},
+	}
+
+	destDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), destDir, src)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(obj.Objects) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(obj.Objects))
+	}
+
+	if err = obj.Processor(context.Background(), obj.Objects[0]); err != nil {
+		t.Fatalf("Processor failed: %v", err)
+	}
+
+	expectedURL := "https://gitlab.example.com/api/v4/projects/group%2Fproject/jobs/artifacts/main/raw/dist/app.tar.gz?job=build+linux"
+	if md.lastURL != expectedURL {
+		t.Errorf("expected URL %s, got %s", expectedURL, md.lastURL)
+	}
+	if expectedDest := filepath.Join(destDir, "app.tar.gz"); md.lastDest != expectedDest {
+		t.Errorf("expected dest %s, got %s", expectedDest, md.lastDest)
+	}
+	if md.headers["PRIVATE-TOKEN"] != "secret" {
+		t.Errorf("expected token header, got %v", md.headers)
+	}
+}
+
+func TestFetcher_Fetch_ArtifactsArchive(t *testing.T) {
+	t.Parallel()
+
+	md := &mockDownloader{}
+	fetcher := gitlab.NewFetcher(md, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	src := types.Source{
+		Gitlab: &types.GitlabOptions{
+			Host:      "https://gitlab.example.com",
+			Project:   "project",
+			Ref:       "v1.0.0",
+			Artifacts: &types.GitlabArtifactsOptions{Job: "build"},
+		},
+	}
+
+	destDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), destDir, src)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if err = obj.Processor(context.Background(), obj.Objects[0]); err != nil {
+		t.Fatalf("Processor failed: %v", err)
+	}
+
+	expectedURL := "https://gitlab.example.com/api/v4/projects/project/jobs/artifacts/v1.0.0/download?job=build"
+	if md.lastURL != expectedURL {
+		t.Errorf("expected URL %s, got %s", expectedURL, md.lastURL)
+	}
+	if expectedDest := filepath.Join(destDir, "artifacts.zip"); md.lastDest != expectedDest {
+		t.Errorf("expected dest %s, got %s", expectedDest, md.lastDest)
+	}
+}
+
+func TestFetcher_Fetch_Package(t *testing.T) {
+	t.Parallel()
+
+	packages := []gitlab.Package{
+		{ID: 1, Name: "models", Version: "1.0.0"},
+		{ID: 2, Name: "models", Version: "2.0.0"},
+	}
+	firstPage := []gitlab.PackageFile{
+		{ID: 10, FileName: "model.bin"},
+		{ID: 11, FileName: "README.md"},
+	}
+	secondPage := []gitlab.PackageFile{
+		{ID: 12, FileName: "weights.bin"},
+		{ID: 13, FileName: "model.bin"},
+	}
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v4/projects/project/packages":
+			_ = json.NewEncoder(w).Encode(packages)
+		case "/api/v4/projects/project/packages/2/package_files":
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				_ = json.NewEncoder(w).Encode(firstPage)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(secondPage)
+		default:
+			t.Errorf("unexpected request URL: %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer apiServer.Close()
+
+	md := &mockDownloader{}
+	fetcher := gitlab.NewFetcher(md, slog.New(slog.NewTextHandler(os.Stdout, nil)), gitlab.WithHTTPClient(apiServer.Client()))
+
+	src := types.Source{
+		Gitlab: &types.GitlabOptions{
+			Host:    apiServer.URL,
+			Project: "project",
+			Token:   "secret",
+			Package: &types.GitlabPackageOptions{
+				Name:    "models",
+				Version: "2.0.0",
+				Files:   []string{"*.bin"},
+			},
+		},
+	}
+
+	destDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), destDir, src)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(obj.Objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(obj.Objects))
+	}
+
+	expected := map[string]string{
+		apiServer.URL + "/api/v4/projects/project/packages/generic/models/2.0.0/model.bin":   filepath.Join(destDir, "model.bin"),
+		apiServer.URL + "/api/v4/projects/project/packages/generic/models/2.0.0/weights.bin": filepath.Join(destDir, "weights.bin"),
+	}
+	for _, o := range obj.Objects {
+		if dest, ok := expected[o.ActualPath]; !ok || dest != o.Path {
+			t.Errorf("unexpected object %+v", o)
+		}
+	}
+}
+
+func TestFetcher_Fetch_PackageNotFound(t *testing.T) {
+	t.Parallel()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]gitlab.Package{{ID: 1, Name: "models", Version: "1.0.0"}})
+	}))
+	defer apiServer.Close()
+
+	md := &mockDownloader{}
+	fetcher := gitlab.NewFetcher(md, slog.New(slog.NewTextHandler(os.Stdout, nil)), gitlab.WithHTTPClient(apiServer.Client()))
+
+	src := types.Source{
+		Gitlab: &types.GitlabOptions{
+			Host:    apiServer.URL,
+			Project: "project",
+			Package: &types.GitlabPackageOptions{Name: "models", Version: "3.0.0"},
+		},
+	}
+
+	_, err := fetcher.Fetch(context.Background(), t.TempDir(), src)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestFetcher_Fetch_InvalidPackageOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts types.GitlabOptions
+		want string
+	}{
+		{
+			name: "artifacts and package",
+			opts: types.GitlabOptions{
+				Artifacts: &types.GitlabArtifactsOptions{Job: "build"},
+				Package:   &types.GitlabPackageOptions{Name: "models", Version: "1.0.0"},
+			},
+			want: "cannot both be set",
+		},
+		{
+			name: "malformed file pattern",
+			opts: types.GitlabOptions{
+				Package: &types.GitlabPackageOptions{Name: "models", Version: "1.0.0", Files: []string{"[abc"}},
+			},
+			want: "invalid glob pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := gitlab.NewFetcher(&mockDownloader{}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+			tt.opts.Host = "https://gitlab.invalid"
+			tt.opts.Project = "project"
+			_, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{Gitlab: &tt.opts})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFetcher_Fetch_DetectsFiles(t *testing.T) {
+	t.Parallel()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("path") {
+		case "Makefile":
+			http.Error(w, `{"message":"404 Tree Not Found"}`, http.StatusNotFound)
+		case "configs.d":
+			_ = json.NewEncoder(w).Encode([]gitlab.File{{Name: "app.yaml", Type: "blob", Path: "configs.d/app.yaml"}})
+		default:
+			_ = json.NewEncoder(w).Encode([]gitlab.File{})
+		}
+	}))
+	defer apiServer.Close()
+
+	fetcher := gitlab.NewFetcher(&mockDownloader{}, slog.New(slog.NewTextHandler(os.Stdout, nil)), gitlab.WithHTTPClient(apiServer.Client()))
+
+	destDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), destDir, types.Source{
+		Gitlab: &types.GitlabOptions{
+			Host:    apiServer.URL,
+			Project: "project",
+			Ref:     "main",
+			Paths:   []types.RepoPath{{Path: "Makefile"}, {Path: "configs.d"}, {Path: "LICENSE"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	var got []string
+	for _, o := range obj.Objects {
+		got = append(got, o.ActualPath)
+	}
+	if want := []string{"Makefile", "configs.d/app.yaml", "LICENSE"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	_, err = fetcher.Fetch(context.Background(), destDir, types.Source{
+		Gitlab: &types.GitlabOptions{
+			Host:    apiServer.URL,
+			Project: "project",
+			Ref:     "main",
+			Paths:   []types.RepoPath{{Path: "Makefile/"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
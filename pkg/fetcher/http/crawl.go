@@ -0,0 +1,160 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	"golang.org/x/net/html"
+)
+
+// crawler walks Apache and nginx style autoindex pages below root. Only links that stay below the
+// root URL are followed, so parent directory and column sorting links are ignored.
+type crawler struct {
+	client  *http.Client
+	headers map[string]string
+	opts    types.HttpCrawlOptions
+	root    *url.URL
+	visited map[string]bool
+	logger  *slog.Logger
+}
+
+type crawledFile struct {
+	URL      string
+	Relative string
+}
+
+func (f *Fetcher) crawl(ctx context.Context, uri string, headers map[string]string, opts types.HttpCrawlOptions) ([]crawledFile, error) {
+	root, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index url %q: %w", uri, err)
+	}
+	if !strings.HasSuffix(root.Path, "/") {
+		root.Path += "/"
+		root.RawPath = ""
+	}
+	root.RawQuery, root.Fragment = "", ""
+
+	c := &crawler{
+		client:  f.client,
+		headers: headers,
+		opts:    opts,
+		root:    root,
+		visited: map[string]bool{},
+		logger:  f.logger,
+	}
+	return c.walk(ctx, root, 1)
+}
+
+func (c *crawler) walk(ctx context.Context, page *url.URL, depth int) ([]crawledFile, error) {
+	c.visited[page.Path] = true
+	c.logger.Info("reading index", slog.String("url", page.String()), slog.Int("depth", depth))
+
+	links, err := c.links(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []crawledFile
+	for _, link := range links {
+		rel, ok := c.relative(link)
+		if !ok || c.visited[link.Path] {
+			continue
+		}
+
+		if strings.HasSuffix(link.Path, "/") {
+			if c.opts.MaxDepth > 0 && depth >= c.opts.MaxDepth {
+				continue
+			}
+			nested, nestedErr := c.walk(ctx, link, depth+1)
+			if nestedErr != nil {
+				return nil, nestedErr
+			}
+			files = append(files, nested...)
+			continue
+		}
+
+		c.visited[link.Path] = true
+		if utils.MatchPatterns(rel, c.opts.Include, c.opts.Exclude) {
+			files = append(files, crawledFile{URL: link.String(), Relative: rel})
+		}
+	}
+
+	return files, nil
+}
+
+// links returns the anchors of an index page, resolved against the final URL of the response so
+// that redirects to a trailing slash are honoured.
+func (c *crawler) links(ctx context.Context, page *url.URL) ([]*url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, page.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range c.headers {
+		req.Header.Add(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index %q: %w", page, err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			c.logger.Warn("error closing response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("index %q returned status %d", page, resp.StatusCode)
+	}
+
+	var links []*url.URL
+	tokenizer := html.NewTokenizer(resp.Body)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return links, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			if string(name) != "a" || !hasAttr {
+				continue
+			}
+			for {
+				key, val, more := tokenizer.TagAttr()
+				if string(key) == "href" {
+					if ref, parseErr := url.Parse(string(val)); parseErr == nil {
+						link := resp.Request.URL.ResolveReference(ref)
+						link.Fragment = ""
+						links = append(links, link)
+					}
+				}
+				if !more {
+					break
+				}
+			}
+		}
+	}
+}
+
+// relative returns the path of link below the root URL. Links with a query string are sorting links.
+func (c *crawler) relative(link *url.URL) (string, bool) {
+	if link.RawQuery != "" || link.Scheme != c.root.Scheme || link.Host != c.root.Host {
+		return "", false
+	}
+	if !strings.HasPrefix(link.Path, c.root.Path) || len(link.Path) <= len(c.root.Path) {
+		return "", false
+	}
+
+	rel := path.Clean(strings.TrimPrefix(link.Path, c.root.Path))
+	if !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,172 @@
+package http_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	httpfetcher "github.com/AdamShannag/volare/pkg/fetcher/http"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+func newIndexServer(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(root)))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func crawl(t *testing.T, server *httptest.Server, uri string, crawlOpts types.HttpCrawlOptions) map[string]string {
+	t.Helper()
+
+	var (
+		targets = map[string]string{}
+		mock    = &MockDownloader{}
+	)
+	f := httpfetcher.NewFetcher(mock, slog.New(slog.NewTextHandler(io.Discard, nil)), httpfetcher.WithHTTPClient(server.Client()))
+
+	mountPath := t.TempDir()
+	obj, err := f.Fetch(context.Background(), mountPath, types.Source{
+		Http: &types.HttpOptions{URI: uri, Crawl: &crawlOpts},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	for _, o := range obj.Objects {
+		rel, _ := filepath.Rel(mountPath, o.Path)
+		targets[filepath.ToSlash(rel)] = strings.TrimPrefix(o.ActualPath, server.URL)
+	}
+	return targets
+}
+
+func TestFetcher_Crawl_Recursive(t *testing.T) {
+	t.Parallel()
+
+	server := newIndexServer(t, map[string]string{
+		"pub/data/a.csv":          "a",
+		"pub/data/b.txt":          "b",
+		"pub/data/2024/c.csv":     "c",
+		"pub/data/2024/old/d.csv": "d",
+		"pub/other/e.csv":         "e",
+	})
+
+	got := crawl(t, server, server.URL+"/pub/data", types.HttpCrawlOptions{})
+	want := map[string]string{
+		"a.csv":          "/pub/data/a.csv",
+		"b.txt":          "/pub/data/b.txt",
+		"2024/c.csv":     "/pub/data/2024/c.csv",
+		"2024/old/d.csv": "/pub/data/2024/old/d.csv",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("expected %q -> %q, got %q", k, v, got[k])
+		}
+	}
+}
+
+func TestFetcher_Crawl_DepthAndPatterns(t *testing.T) {
+	t.Parallel()
+
+	server := newIndexServer(t, map[string]string{
+		"a.csv":          "a",
+		"b.txt":          "b",
+		"2024/c.csv":     "c",
+		"2024/skip.csv":  "s",
+		"2024/old/d.csv": "d",
+	})
+
+	got := crawl(t, server, server.URL+"/", types.HttpCrawlOptions{
+		MaxDepth: 2,
+		Include:  []string{"**/*.csv"},
+		Exclude:  []string{"**/skip.csv"},
+	})
+
+	var keys []string
+	for k := range got {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	if want := []string{"2024/c.csv", "a.csv"}; !slices.Equal(keys, want) {
+		t.Errorf("expected %v, got %v", want, keys)
+	}
+}
+
+func TestFetcher_Crawl_ApacheIndex(t *testing.T) {
+	t.Parallel()
+
+	const rootIndex = `<html><body><h1>Index of /mirror</h1><table>
+<tr><th><a href="?C=N;O=D">Name</a></th><th><a href="?C=M;O=A">Last modified</a></th></tr>
+<tr><td><a href="/">Parent Directory</a></td></tr>
+<tr><td><a href="release%201.0/">release 1.0/</a></td></tr>
+<tr><td><a href="https://elsewhere.example.com/x.iso">mirror</a></td></tr>
+<tr><td><a href="checksums.txt#top">checksums.txt</a></td></tr>
+</table></body></html>`
+	const releaseIndex = `<html><body><pre><a href="../">../</a>
+<a href="image.iso">image.iso</a>
+<a href="/mirror/release%201.0/notes.txt">notes.txt</a>
+</pre></body></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mirror/":
+			_, _ = io.WriteString(w, rootIndex)
+		case "/mirror/release 1.0/":
+			_, _ = io.WriteString(w, releaseIndex)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	got := crawl(t, server, server.URL+"/mirror/", types.HttpCrawlOptions{})
+	want := map[string]string{
+		"checksums.txt":         "/mirror/checksums.txt",
+		"release 1.0/image.iso": "/mirror/release%201.0/image.iso",
+		"release 1.0/notes.txt": "/mirror/release%201.0/notes.txt",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("expected %q -> %q, got %q", k, v, got[k])
+		}
+	}
+}
+
+func TestFetcher_Crawl_IndexError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	f := httpfetcher.NewFetcher(&MockDownloader{}, slog.New(slog.NewTextHandler(io.Discard, nil)), httpfetcher.WithHTTPClient(server.Client()))
+	_, err := f.Fetch(context.Background(), t.TempDir(), types.Source{
+		Http: &types.HttpOptions{URI: server.URL + "/missing/", Crawl: &types.HttpCrawlOptions{}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
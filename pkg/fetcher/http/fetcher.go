@@ -2,8 +2,14 @@ package http
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/AdamShannag/volare/pkg/downloader"
 	"github.com/AdamShannag/volare/pkg/fetcher"
@@ -11,48 +17,190 @@ import (
 	"github.com/AdamShannag/volare/pkg/utils"
 )
 
+type Option func(*Fetcher)
+
 type Fetcher struct {
+	client     *http.Client
 	downloader downloader.Downloader
 	logger     *slog.Logger
 }
 
-func NewFetcher(downloader downloader.Downloader, logger *slog.Logger) *Fetcher {
-	return &Fetcher{
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *Fetcher) {
+		f.client = client
+	}
+}
+
+func NewFetcher(downloader downloader.Downloader, logger *slog.Logger, opts ...Option) *Fetcher {
+	f := &Fetcher{
+		client:     http.DefaultClient,
 		downloader: downloader,
 		logger:     logger,
 	}
-}
+	for _, opt := range opts {
+		opt(f)
+	}
 
-func (f *Fetcher) Fetch(_ context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
-	f.logger.Info("downloading file", slog.String("url", src.Http.URI))
+	return f
+}
 
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
 	resolvedHeaders := make(map[string]string, len(src.Http.Headers))
 	for k, v := range src.Http.Headers {
 		resolvedHeaders[k] = utils.FromEnv(v)
 	}
 
-	workers := 1
+	if src.Http.Crawl != nil {
+		if err := utils.ValidatePatterns(src.Http.Crawl.Include, src.Http.Crawl.Exclude); err != nil {
+			return nil, err
+		}
+		files, err := f.crawl(ctx, src.Http.URI, resolvedHeaders, *src.Http.Crawl)
+		if err != nil {
+			return nil, fmt.Errorf("crawling %q: %w", src.Http.URI, err)
+		}
+
+		var objects []types.ObjectToDownload
+		for _, fl := range files {
+			objects = append(objects, types.ObjectToDownload{
+				ActualPath: fl.URL,
+				Path:       filepath.Join(mountPath, filepath.FromSlash(fl.Relative)),
+			})
+		}
+		if len(objects) == 0 {
+			f.logger.Info("no files found", slog.String("url", src.Http.URI))
+		}
+
+		objects, err = utils.SelectObjectsBy(mountPath, objects, src, targetPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return &fetcher.Object{
+			Processor: func(ctx context.Context, j types.ObjectToDownload) error {
+				return f.download(ctx, j, nil, resolvedHeaders)
+			},
+			Objects: objects,
+			Workers: src.Http.Workers,
+		}, nil
+	}
+
+	var objects []types.ObjectToDownload
+	mirrors := map[string][]string{}
+	add := func(uri, dest string, fileMirrors []string) error {
+		if _, ok := mirrors[dest]; ok {
+			return fmt.Errorf("more than one file would be written to %q", dest)
+		}
+		mirrors[dest] = fileMirrors
+		objects = append(objects, types.ObjectToDownload{ActualPath: uri, Path: dest})
+		return nil
+	}
+
+	if src.Http.URI != "" {
+		dest, err := resolveFilePath(mountPath, src)
+		if err != nil {
+			return nil, err
+		}
+		if err = add(src.Http.URI, dest, src.Http.Mirrors); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range src.Http.Files {
+		name, err := fileName(file.URI)
+		if err != nil {
+			return nil, err
+		}
+		if err = add(file.URI, filepath.Join(mountPath, name), file.Mirrors); err != nil {
+			return nil, err
+		}
+	}
+
+	filtered, err := utils.FilterObjectsBy(mountPath, objects, src.Include, src.Exclude, targetPath)
+	if err != nil {
+		return nil, err
+	}
+	objects, err = utils.MapObjectsBy(mountPath, filtered, src.PathRules, targetPath)
+	if err != nil {
+		return nil, err
+	}
+	mappedMirrors := make(map[string][]string, len(objects))
+	for i := range objects {
+		mappedMirrors[objects[i].Path] = mirrors[filtered[i].Path]
+	}
+
 	return &fetcher.Object{
 		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
-			return f.downloader.Download(ctx, j.ActualPath, resolvedHeaders, j.Path)
+			return f.download(ctx, j, mappedMirrors[j.Path], resolvedHeaders)
 		},
-		Objects: []types.ObjectToDownload{
-			{
-				ActualPath: src.Http.URI,
-				Path:       resolveFilePaths(mountPath, src.Http.URI),
-			},
-		},
-		Workers: &workers,
+		Objects: objects,
+		Workers: src.Http.Workers,
 	}, nil
 }
 
-func resolveFilePaths(targetPath, uri string) (fullFilePath string) {
-	if filepath.Ext(targetPath) != "" {
-		fullFilePath = targetPath
-	} else {
-		filename := filepath.Base(uri)
-		fullFilePath = filepath.Join(targetPath, filename)
+func targetPath(o *types.ObjectToDownload) *string {
+	return &o.Path
+}
+
+// download tries the primary URL and then each mirror in order, returning every error if all of them fail.
+func (f *Fetcher) download(ctx context.Context, job types.ObjectToDownload, mirrors []string, headers map[string]string) error {
+	var errs []error
+	for i, uri := range append([]string{job.ActualPath}, mirrors...) {
+		if i == 0 {
+			f.logger.Info("downloading file", slog.String("url", uri))
+		} else {
+			f.logger.Warn("trying mirror", slog.String("url", uri), slog.String("primary", job.ActualPath))
+		}
+
+		err := f.downloader.Download(ctx, uri, headers, job.Path)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fileName returns the last path segment of uri, ignoring any query string.
+func fileName(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", uri, err)
+	}
+	name := path.Base(u.Path)
+	if name == "." || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("cannot derive a file name from %q", uri)
+	}
+	return name, nil
+}
+
+// resolveFilePath returns where the file at uri is written. A targetPath ending in a slash is a directory, and
+// keeps the name of the file. Without a trailing slash or targetType, a targetPath with an extension names the
+// file itself.
+func resolveFilePath(mountPath string, src types.Source) (string, error) {
+	kind := src.Http.TargetType
+	if kind == "" {
+		switch {
+		case strings.HasSuffix(src.TargetPath, "/"):
+			kind = types.PathTypeDir
+		case filepath.Ext(mountPath) != "":
+			kind = types.PathTypeFile
+		default:
+			kind = types.PathTypeDir
+		}
 	}
 
-	return fullFilePath
+	switch kind {
+	case types.PathTypeFile:
+		return mountPath, nil
+	case types.PathTypeDir:
+		name, err := fileName(src.Http.URI)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(mountPath, name), nil
+	default:
+		return "", fmt.Errorf("unsupported http targetType %q", kind)
+	}
 }
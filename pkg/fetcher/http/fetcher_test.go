@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
 	httpfetcher "github.com/AdamShannag/volare/pkg/fetcher/http"
@@ -140,3 +142,207 @@ func TestFetcher_Fetch_ProcessorError(t *testing.T) {
 		t.Fatalf("expected download error, got: %v", err)
 	}
 }
+
+func TestFetcher_Fetch_MultipleFilesWithMirrors(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	workers := 3
+	src := types.Source{
+		Http: &types.HttpOptions{
+			Files: []types.HttpFileOptions{
+				{URI: "https://primary.example.com/data/a.csv?sig=1", Mirrors: []string{"https://mirror1.example.com/a.csv", "https://mirror2.example.com/a.csv"}},
+				{URI: "https://primary.example.com/data/b.csv"},
+			},
+			Workers: &workers,
+		},
+	}
+
+	var (
+		mu        sync.Mutex
+		attempted []string
+	)
+	mock := &MockDownloader{
+		DownloadFunc: func(ctx context.Context, url string, headers map[string]string, dest string) error {
+			mu.Lock()
+			attempted = append(attempted, url)
+			mu.Unlock()
+			if strings.Contains(url, "primary.example.com/data/a.csv") || strings.Contains(url, "mirror1") {
+				return errors.New("unexpected HTTP status 503")
+			}
+			return os.WriteFile(dest, []byte(url), 0o644)
+		},
+	}
+
+	fetcher := httpfetcher.NewFetcher(mock, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	obj, err := fetcher.Fetch(context.Background(), tmpDir, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if obj.Workers == nil || *obj.Workers != workers {
+		t.Errorf("expected %d workers, got %v", workers, obj.Workers)
+	}
+	if len(obj.Objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(obj.Objects))
+	}
+
+	for _, o := range obj.Objects {
+		if err = obj.Processor(context.Background(), o); err != nil {
+			t.Fatalf("processor returned error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "a.csv"))
+	if err != nil || string(data) != "https://mirror2.example.com/a.csv" {
+		t.Errorf("expected a.csv from the second mirror, got %q (%v)", data, err)
+	}
+	if _, err = os.Stat(filepath.Join(tmpDir, "b.csv")); err != nil {
+		t.Errorf("expected b.csv to be downloaded: %v", err)
+	}
+
+	want := []string{
+		"https://primary.example.com/data/a.csv?sig=1",
+		"https://mirror1.example.com/a.csv",
+		"https://mirror2.example.com/a.csv",
+		"https://primary.example.com/data/b.csv",
+	}
+	if !slices.Equal(attempted, want) {
+		t.Errorf("expected attempts %v, got %v", want, attempted)
+	}
+}
+
+func TestFetcher_Fetch_AllMirrorsFail(t *testing.T) {
+	t.Parallel()
+
+	src := types.Source{
+		Http: &types.HttpOptions{
+			URI:     "https://primary.example.com/file.txt",
+			Mirrors: []string{"https://mirror.example.com/file.txt"},
+		},
+	}
+
+	mock := &MockDownloader{
+		DownloadFunc: func(ctx context.Context, url string, headers map[string]string, dest string) error {
+			return errors.New("failed " + url)
+		},
+	}
+
+	fetcher := httpfetcher.NewFetcher(mock, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	obj, err := fetcher.Fetch(context.Background(), t.TempDir(), src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = obj.Processor(context.Background(), obj.Objects[0])
+	if err == nil || !strings.Contains(err.Error(), "failed https://primary.example.com/file.txt") ||
+		!strings.Contains(err.Error(), "failed https://mirror.example.com/file.txt") {
+		t.Fatalf("expected errors from every url, got: %v", err)
+	}
+}
+
+func TestFetcher_Fetch_InvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		files []types.HttpFileOptions
+		want  string
+	}{
+		{
+			name:  "duplicate target",
+			files: []types.HttpFileOptions{{URI: "https://a.example.com/x.bin"}, {URI: "https://b.example.com/x.bin"}},
+			want:  "more than one file",
+		},
+		{
+			name:  "no file name",
+			files: []types.HttpFileOptions{{URI: "https://a.example.com/"}},
+			want:  "cannot derive a file name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := httpfetcher.NewFetcher(&MockDownloader{}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+			_, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{Http: &types.HttpOptions{Files: tt.files}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFetcher_Fetch_PathRulesKeepMirrors(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	src := types.Source{
+		Http: &types.HttpOptions{
+			Files: []types.HttpFileOptions{
+				{URI: "https://primary.example.com/a.csv", Mirrors: []string{"https://mirror.example.com/a.csv"}},
+			},
+		},
+		PathRules: []types.PathRule{{Match: `^(.*)\.csv$`, Replace: "data/$1-v1.csv"}},
+	}
+
+	mock := &MockDownloader{
+		DownloadFunc: func(ctx context.Context, url string, headers map[string]string, dest string) error {
+			if strings.Contains(url, "primary") {
+				return errors.New("unexpected HTTP status 503")
+			}
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return err
+			}
+			return os.WriteFile(dest, []byte(url), 0o644)
+		},
+	}
+
+	fetcher := httpfetcher.NewFetcher(mock, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	obj, err := fetcher.Fetch(context.Background(), tmpDir, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = obj.Processor(context.Background(), obj.Objects[0]); err != nil {
+		t.Fatalf("processor returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "data", "a-v1.csv"))
+	if err != nil || string(data) != "https://mirror.example.com/a.csv" {
+		t.Errorf("expected renamed file from the mirror, got %q (%v)", data, err)
+	}
+}
+
+func TestFetcher_Fetch_TargetType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		mountPath  string
+		targetPath string
+		targetType types.PathType
+		want       string
+	}{
+		{name: "trailing slash", mountPath: "/mnt/configs.d", targetPath: "configs.d/", want: "/mnt/configs.d/app.yaml"},
+		{name: "explicit dir", mountPath: "/mnt/configs.d", targetPath: "configs.d", targetType: types.PathTypeDir, want: "/mnt/configs.d/app.yaml"},
+		{name: "explicit file", mountPath: "/mnt/Makefile", targetPath: "Makefile", targetType: types.PathTypeFile, want: "/mnt/Makefile"},
+		{name: "extension", mountPath: "/mnt/app.yml", targetPath: "app.yml", want: "/mnt/app.yml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := httpfetcher.NewFetcher(&MockDownloader{}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+			obj, err := fetcher.Fetch(context.Background(), tt.mountPath, types.Source{
+				TargetPath: tt.targetPath,
+				Http: &types.HttpOptions{
+					URI:        "https://example.com/files/app.yaml?token=abc",
+					TargetType: tt.targetType,
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := obj.Objects[0].Path; got != filepath.FromSlash(tt.want) {
+				t.Errorf("expected Path %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
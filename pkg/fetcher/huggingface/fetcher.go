@@ -0,0 +1,306 @@
+package huggingface
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/downloader"
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+const (
+	DefaultEndpoint = "https://huggingface.co"
+	defaultRevision = "main"
+	entryTypeFile   = "file"
+)
+
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+type Option func(*Fetcher)
+
+type Fetcher struct {
+	client     *http.Client
+	downloader downloader.Downloader
+	baseURL    string
+	logger     *slog.Logger
+}
+
+type TreeEntry struct {
+	Type string   `json:"type"`
+	Path string   `json:"path"`
+	Size int64    `json:"size"`
+	OID  string   `json:"oid"`
+	LFS  *LFSInfo `json:"lfs,omitempty"`
+}
+
+type LFSInfo struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *Fetcher) {
+		f.client = client
+	}
+}
+
+func WithBaseURL(baseURL string) Option {
+	return func(f *Fetcher) {
+		f.baseURL = baseURL
+	}
+}
+
+func NewFetcher(downloader downloader.Downloader, logger *slog.Logger, opts ...Option) fetcher.Fetcher {
+	f := &Fetcher{
+		client:     http.DefaultClient,
+		downloader: downloader,
+		baseURL:    DefaultEndpoint,
+		logger:     logger,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	hfOpts := *src.HuggingFace
+	if err := utils.ValidatePatterns(hfOpts.Include, hfOpts.Exclude); err != nil {
+		return nil, err
+	}
+
+	entries, err := f.list(ctx, hfOpts)
+	if err != nil {
+		return nil, fmt.Errorf("listing Hugging Face repository %q: %w", hfOpts.Repo, err)
+	}
+
+	var filesToDownload []types.ObjectToDownload
+	for _, entry := range entries {
+		if entry.Type != entryTypeFile || !utils.MatchPatterns(entry.Path, hfOpts.Include, hfOpts.Exclude) {
+			continue
+		}
+
+		file := types.ObjectToDownload{ActualPath: entry.Path, Size: entry.Size}
+		if entry.LFS != nil {
+			file.Version = entry.LFS.OID
+			file.Size = entry.LFS.Size
+		}
+		filesToDownload = append(filesToDownload, file)
+	}
+
+	if len(filesToDownload) == 0 {
+		f.logger.Info("no files matched", "repo", hfOpts.Repo, "include", hfOpts.Include, "exclude", hfOpts.Exclude)
+	}
+
+	filesToDownload, err = utils.SelectObjects(mountPath, filesToDownload, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
+			return f.download(ctx, mountPath, j, hfOpts)
+		},
+		Objects: filesToDownload,
+		Workers: hfOpts.Workers,
+	}, nil
+}
+
+func (f *Fetcher) list(ctx context.Context, hfOpts types.HuggingFaceOptions) ([]TreeEntry, error) {
+	apiType, _, err := repoTypePaths(hfOpts.RepoType)
+	if err != nil {
+		return nil, err
+	}
+
+	nextURL := fmt.Sprintf("%s/api/%s/%s/tree/%s?recursive=true",
+		f.endpoint(hfOpts),
+		apiType,
+		hfOpts.Repo,
+		url.PathEscape(revision(hfOpts)),
+	)
+
+	var entries []TreeEntry
+	for nextURL != "" {
+		page, next, pageErr := f.listPage(ctx, hfOpts, nextURL)
+		if pageErr != nil {
+			return nil, pageErr
+		}
+		entries = append(entries, page...)
+		nextURL = next
+	}
+
+	return entries, nil
+}
+
+func (f *Fetcher) listPage(ctx context.Context, hfOpts types.HuggingFaceOptions, apiURL string) ([]TreeEntry, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range authHeaders(hfOpts) {
+		req.Header.Add(k, v)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list repository tree: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			f.logger.Warn("error closing response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("Hugging Face API returned status %d", resp.StatusCode)
+	}
+
+	var entries []TreeEntry
+	if err = json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, "", fmt.Errorf("failed to decode tree: %w", err)
+	}
+
+	var next string
+	if m := nextLinkPattern.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+		next = m[1]
+	}
+
+	return entries, next, nil
+}
+
+func (f *Fetcher) download(ctx context.Context, mountPath string, file types.ObjectToDownload, hfOpts types.HuggingFaceOptions) error {
+	_, urlPrefix, err := repoTypePaths(hfOpts.RepoType)
+	if err != nil {
+		return err
+	}
+
+	segments := strings.Split(file.ActualPath, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	resolveURL := fmt.Sprintf("%s/%s%s/resolve/%s/%s",
+		f.endpoint(hfOpts),
+		urlPrefix,
+		hfOpts.Repo,
+		url.PathEscape(revision(hfOpts)),
+		strings.Join(segments, "/"),
+	)
+
+	targetPath := utils.ResolveTargetPath(mountPath, file)
+	f.logger.Info("downloading file", slog.String("repo", hfOpts.Repo), slog.String("file", file.ActualPath))
+	if file.Version == "" {
+		return f.downloader.Download(ctx, resolveURL, authHeaders(hfOpts), targetPath)
+	}
+
+	if err = f.downloadLFS(ctx, resolveURL, hfOpts, targetPath, file.Version); err != nil {
+		return fmt.Errorf("failed to verify LFS file %q: %w", file.ActualPath, err)
+	}
+	return nil
+}
+
+// downloadLFS hashes the response while writing it, so files that are extracted or decompressed on the way to
+// targetPath are verified as well.
+func (f *Fetcher) downloadLFS(ctx context.Context, resolveURL string, hfOpts types.HuggingFaceOptions, targetPath, expected string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resolveURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range authHeaders(hfOpts) {
+		req.Header.Add(k, v)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %q: %w", resolveURL, err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			f.logger.Warn("error closing response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status %d fetching %q", resp.StatusCode, resolveURL)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
+	}
+
+	fh, err := extract.Create(ctx, targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", targetPath, err)
+	}
+
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(fh, hasher), resp.Body)
+	if err == nil {
+		if got := hex.EncodeToString(hasher.Sum(nil)); got != expected {
+			err = fmt.Errorf("sha256 mismatch: expected %s, got %s", expected, got)
+		}
+	}
+	if err != nil {
+		extract.Abort(fh, err)
+	}
+	if cerr := fh.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil && !extract.Applies(ctx, targetPath) {
+		if rmErr := os.Remove(targetPath); rmErr != nil {
+			f.logger.Warn("error removing unverified file", "file", targetPath, "error", rmErr)
+		}
+	}
+	return err
+}
+
+func (f *Fetcher) endpoint(hfOpts types.HuggingFaceOptions) string {
+	if hfOpts.Endpoint != "" {
+		return strings.TrimSuffix(hfOpts.Endpoint, "/")
+	}
+	return strings.TrimSuffix(f.baseURL, "/")
+}
+
+// repoTypePaths returns the API collection and the download URL prefix of a repository type.
+func repoTypePaths(repoType types.HuggingFaceRepoType) (string, string, error) {
+	switch repoType {
+	case "", types.HuggingFaceRepoModel:
+		return "models", "", nil
+	case types.HuggingFaceRepoDataset:
+		return "datasets", "datasets/", nil
+	case types.HuggingFaceRepoSpace:
+		return "spaces", "spaces/", nil
+	default:
+		return "", "", fmt.Errorf("unsupported Hugging Face repo type %q", repoType)
+	}
+}
+
+func revision(hfOpts types.HuggingFaceOptions) string {
+	if hfOpts.Revision == "" {
+		return defaultRevision
+	}
+	return hfOpts.Revision
+}
+
+func authHeaders(hfOpts types.HuggingFaceOptions) map[string]string {
+	headers := map[string]string{}
+	if hfOpts.Token != "" {
+		headers["Authorization"] = "Bearer " + utils.FromEnv(hfOpts.Token)
+	}
+	return headers
+}
@@ -0,0 +1,307 @@
+package huggingface_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/downloader"
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher/huggingface"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+type hubRepo struct {
+	files map[string]string
+	lfs   map[string]bool
+}
+
+// newHub mocks the tree API (paginated one entry per page) and the resolve endpoint, which redirects
+// LFS files to a separate CDN path like the real Hub does.
+func newHub(t *testing.T, collection, urlPrefix, repo string, hub hubRepo, corruptLFS bool) *httptest.Server {
+	t.Helper()
+
+	var names []string
+	for name := range hub.files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hf_secret" && !strings.HasPrefix(r.URL.Path, "/cdn/") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		treePath := "/api/" + collection + "/" + repo + "/tree/main"
+		resolvePrefix := "/" + urlPrefix + repo + "/resolve/main/"
+		switch {
+		case r.URL.Path == treePath:
+			if r.URL.Query().Get("recursive") != "true" {
+				t.Errorf("expected recursive listing, got %q", r.URL.RawQuery)
+			}
+
+			page := 0
+			if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+				page = len(cursor)
+			}
+			entries := []huggingface.TreeEntry{{Type: "directory", Path: "dir"}}
+			if page < len(names) {
+				name := names[page]
+				content := hub.files[name]
+				entry := huggingface.TreeEntry{Type: "file", Path: name, Size: int64(len(content))}
+				if hub.lfs[name] {
+					sum := sha256.Sum256([]byte(content))
+					entry.LFS = &huggingface.LFSInfo{OID: hex.EncodeToString(sum[:]), Size: int64(len(content))}
+					entry.Size = 134
+				}
+				entries = append(entries, entry)
+			}
+			if page+1 < len(names) {
+				w.Header().Set("Link", "<"+server.URL+treePath+"?recursive=true&cursor="+strings.Repeat("x", page+1)+`>; rel="next"`)
+			}
+			_ = json.NewEncoder(w).Encode(entries)
+		case strings.HasPrefix(r.URL.Path, resolvePrefix):
+			name := strings.TrimPrefix(r.URL.Path, resolvePrefix)
+			if _, ok := hub.files[name]; !ok {
+				http.NotFound(w, r)
+				return
+			}
+			if hub.lfs[name] {
+				http.Redirect(w, r, server.URL+"/cdn/"+name, http.StatusFound)
+				return
+			}
+			_, _ = io.WriteString(w, hub.files[name])
+		case strings.HasPrefix(r.URL.Path, "/cdn/"):
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("token must not be forwarded to the CDN")
+			}
+			content := hub.files[strings.TrimPrefix(r.URL.Path, "/cdn/")]
+			if corruptLFS {
+				content = strings.ToUpper(content)
+			}
+			_, _ = io.WriteString(w, content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func fetchAll(t *testing.T, opts types.HuggingFaceOptions) (string, error) {
+	t.Helper()
+	return fetchSource(t, types.Source{Type: types.SourceTypeHUGGINGFACE, HuggingFace: &opts})
+}
+
+// fetchSource runs every object through extract.Processor like the populator does.
+func fetchSource(t *testing.T, src types.Source) (string, error) {
+	t.Helper()
+
+	// The mock CDN lives on the same host, so drop the token on redirects the way cross-host redirects do.
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		req.Header.Del("Authorization")
+		return nil
+	}}
+
+	f := huggingface.NewFetcher(downloader.NewHTTPDownloader(downloader.WithHTTPClient(client)), slog.New(slog.NewTextHandler(io.Discard, nil)), huggingface.WithHTTPClient(client))
+	mountPath := t.TempDir()
+	obj, err := f.Fetch(context.Background(), mountPath, src)
+	if err != nil {
+		return mountPath, err
+	}
+	process := extract.Processor(src, obj.Processor)
+	for _, o := range obj.Objects {
+		if err = process(context.Background(), o); err != nil {
+			return mountPath, err
+		}
+	}
+	return mountPath, nil
+}
+
+func listFiles(t *testing.T, root string) []string {
+	t.Helper()
+
+	var files []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk %q: %v", root, err)
+	}
+	slices.Sort(files)
+	return files
+}
+
+var testRepo = hubRepo{
+	files: map[string]string{
+		"config.json":                  `{"model_type":"bert"}`,
+		"model.safetensors":            "weights",
+		"onnx/model.onnx":              "onnx weights",
+		"tokenizer/tokenizer.json":     `{"version":"1.0"}`,
+		"tokenizer/special_token.json": `{}`,
+	},
+	lfs: map[string]bool{"model.safetensors": true, "onnx/model.onnx": true},
+}
+
+func TestFetcher_Fetch_Model(t *testing.T) {
+	server := newHub(t, "models", "", "acme/bert", testRepo, false)
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{
+			name: "all files",
+			want: []string{"config.json", "model.safetensors", "onnx/model.onnx", "tokenizer/special_token.json", "tokenizer/tokenizer.json"},
+		},
+		{
+			name:    "include and exclude",
+			include: []string{"*.json", "**/*.json", "*.safetensors"},
+			exclude: []string{"tokenizer/special_*"},
+			want:    []string{"config.json", "model.safetensors", "tokenizer/tokenizer.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mountPath, err := fetchAll(t, types.HuggingFaceOptions{
+				Repo:     "acme/bert",
+				Include:  tt.include,
+				Exclude:  tt.exclude,
+				Token:    "hf_secret",
+				Endpoint: server.URL,
+			})
+			if err != nil {
+				t.Fatalf("fetch failed: %v", err)
+			}
+
+			if got := listFiles(t, mountPath); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected files %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFetcher_Fetch_LFSContent(t *testing.T) {
+	server := newHub(t, "models", "", "acme/bert", testRepo, false)
+
+	mountPath, err := fetchAll(t, types.HuggingFaceOptions{Repo: "acme/bert", Include: []string{"onnx/**"}, Token: "hf_secret", Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(mountPath, "onnx/model.onnx"))
+	if err != nil || string(data) != "onnx weights" {
+		t.Errorf("unexpected LFS content %q (%v)", data, err)
+	}
+}
+
+func TestFetcher_Fetch_LFSDecompress(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte("compressed weights"))
+	_ = gz.Close()
+	repo := hubRepo{files: map[string]string{"weights.bin.gz": buf.String()}, lfs: map[string]bool{"weights.bin.gz": true}}
+
+	for _, corrupt := range []bool{false, true} {
+		server := newHub(t, "models", "", "acme/gz", repo, corrupt)
+		mountPath, err := fetchSource(t, types.Source{
+			Type:        types.SourceTypeHUGGINGFACE,
+			Decompress:  true,
+			HuggingFace: &types.HuggingFaceOptions{Repo: "acme/gz", Token: "hf_secret", Endpoint: server.URL},
+		})
+		if corrupt {
+			if err == nil {
+				t.Fatal("expected corrupted LFS file to fail")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("fetch failed: %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(mountPath, "weights.bin"))
+		if err != nil || string(data) != "compressed weights" {
+			t.Errorf("unexpected decompressed content %q (%v)", data, err)
+		}
+		if got := listFiles(t, mountPath); !slices.Equal(got, []string{"weights.bin"}) {
+			t.Errorf("unexpected files %v", got)
+		}
+	}
+}
+
+func TestFetcher_Fetch_Dataset(t *testing.T) {
+	server := newHub(t, "datasets", "datasets/", "acme/squad", hubRepo{files: map[string]string{"data/train.csv": "a,b"}}, false)
+
+	mountPath, err := fetchAll(t, types.HuggingFaceOptions{
+		Repo:     "acme/squad",
+		RepoType: types.HuggingFaceRepoDataset,
+		Token:    "hf_secret",
+		Endpoint: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if got := listFiles(t, mountPath); !slices.Equal(got, []string{"data/train.csv"}) {
+		t.Errorf("unexpected files %v", got)
+	}
+}
+
+func TestFetcher_Fetch_Errors(t *testing.T) {
+	server := newHub(t, "models", "", "acme/bert", testRepo, true)
+
+	tests := []struct {
+		name string
+		opts types.HuggingFaceOptions
+		want string
+	}{
+		{
+			name: "lfs checksum mismatch",
+			opts: types.HuggingFaceOptions{Repo: "acme/bert", Include: []string{"model.safetensors"}, Token: "hf_secret", Endpoint: server.URL},
+			want: "sha256 mismatch",
+		},
+		{
+			name: "unauthorized",
+			opts: types.HuggingFaceOptions{Repo: "acme/bert", Endpoint: server.URL},
+			want: "status 401",
+		},
+		{
+			name: "unsupported repo type",
+			opts: types.HuggingFaceOptions{Repo: "acme/bert", RepoType: "collection", Endpoint: server.URL},
+			want: "unsupported Hugging Face repo type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mountPath, err := fetchAll(t, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if files := listFiles(t, mountPath); len(files) != 0 {
+				t.Errorf("expected no files to be left behind, got %v", files)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/fetcher/oci"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+type Fetcher struct {
+	logger *slog.Logger
+}
+
+type filePath struct {
+	Absolute string
+	Relative string
+}
+
+func NewFetcher(logger *slog.Logger) fetcher.Fetcher {
+	return &Fetcher{
+		logger: logger,
+	}
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	opts := *src.Image
+
+	img, err := f.resolve(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "image-*")
+	if err != nil {
+		f.logger.Error("failed to create temp dir", "error", err)
+		return nil, err
+	}
+
+	jobs, err := f.extract(img, tempDir, mountPath, opts.Paths)
+	if err != nil {
+		if rmErr := os.RemoveAll(tempDir); rmErr != nil {
+			f.logger.Warn("error removing temp dir", "dir", tempDir, "error", rmErr)
+		}
+		return nil, err
+	}
+
+	jobs, err = utils.SelectObjectsBy(mountPath, jobs, src, targetPath)
+	if err != nil {
+		if rmErr := os.RemoveAll(tempDir); rmErr != nil {
+			f.logger.Warn("error removing temp dir", "dir", tempDir, "error", rmErr)
+		}
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
+			return f.copy(ctx, j.Path, j.ActualPath)
+		},
+		Objects: jobs,
+		Workers: opts.Workers,
+		Cleanup: func(ctx context.Context) error {
+			f.logger.Info("cleaning up image filesystem", "reference", opts.Reference)
+			return os.RemoveAll(tempDir)
+		},
+	}, nil
+}
+
+func (f *Fetcher) resolve(ctx context.Context, opts types.ImageOptions) (v1.Image, error) {
+	ref, err := oci.ParseReference(opts.Reference, opts.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteOpts := oci.RemoteOptions(ctx, opts.Username, opts.Password, opts.Token)
+	if opts.Platform != "" {
+		platform, platformErr := v1.ParsePlatform(opts.Platform)
+		if platformErr != nil {
+			return nil, fmt.Errorf("invalid platform %q: %w", opts.Platform, platformErr)
+		}
+		remoteOpts = append(remoteOpts, remote.WithPlatform(*platform))
+	}
+
+	img, err := remote.Image(ref, remoteOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve image %q: %w", opts.Reference, err)
+	}
+	return img, nil
+}
+
+func (f *Fetcher) extract(img v1.Image, tempDir, mountPath string, paths []string) ([]types.ObjectToDownload, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image layers: %w", err)
+	}
+
+	a := newApplier(tempDir, paths, f.logger)
+	for i, layer := range layers {
+		digest, digestErr := layer.Digest()
+		if digestErr != nil {
+			return nil, fmt.Errorf("failed to read layer digest: %w", digestErr)
+		}
+		f.logger.Info("applying layer", "index", i, "digest", digest.String())
+
+		if err = f.applyLayer(a, layer); err != nil {
+			return nil, fmt.Errorf("failed to apply layer %s: %w", digest, err)
+		}
+	}
+
+	var jobs []types.ObjectToDownload
+	for _, p := range paths {
+		files, listErr := f.list(tempDir, cleanName(p))
+		if errors.Is(listErr, os.ErrNotExist) {
+			return nil, fmt.Errorf("path %q not found in image", p)
+		}
+		if listErr != nil {
+			return nil, listErr
+		}
+
+		for _, fl := range files {
+			jobs = append(jobs, types.ObjectToDownload{
+				Path: fl.Absolute,
+				ActualPath: utils.ResolveTargetPath(mountPath, types.ObjectToDownload{
+					ActualPath: fl.Relative,
+					Path:       cleanName(p),
+				}),
+			})
+		}
+	}
+
+	return jobs, nil
+}
+
+func (f *Fetcher) applyLayer(a *applier, layer v1.Layer) error {
+	reader, err := layer.Uncompressed()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := reader.Close(); cerr != nil {
+			f.logger.Warn("error closing layer reader", "error", cerr)
+		}
+	}()
+
+	return a.apply(reader)
+}
+
+// list walks the extracted tree without following symlinks, which are recreated as-is by copy.
+func (f *Fetcher) list(root, relBase string) ([]filePath, error) {
+	var files []filePath
+
+	err := filepath.Walk(filepath.Join(root, relBase), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			relPath, relErr := filepath.Rel(root, path)
+			if relErr != nil {
+				return relErr
+			}
+			files = append(files, filePath{
+				Absolute: path,
+				Relative: relPath,
+			})
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+func (f *Fetcher) copy(ctx context.Context, src, dest string) error {
+	f.logger.Info("copying file", "dest", dest)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", dest, err)
+	}
+
+	info, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat source file %q: %w", src, err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, linkErr := os.Readlink(src)
+		if linkErr != nil {
+			return fmt.Errorf("failed to read symlink %q: %w", src, linkErr)
+		}
+		if rmErr := os.Remove(dest); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+			return fmt.Errorf("failed to replace %q: %w", dest, rmErr)
+		}
+		return os.Symlink(link, dest)
+	}
+
+	inFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file %q: %w", src, err)
+	}
+	defer func() {
+		if cerr := inFile.Close(); cerr != nil {
+			f.logger.Warn("error closing file", "error", cerr)
+		}
+	}()
+
+	outFile, err := extract.OpenFile(ctx, dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create destination file %q: %w", dest, err)
+	}
+	defer func() {
+		if cerr := outFile.Close(); cerr != nil {
+			f.logger.Warn("error closing file", "error", cerr)
+		}
+	}()
+
+	if _, err = io.Copy(outFile, inFile); err != nil {
+		extract.Abort(outFile, err)
+		return fmt.Errorf("failed to copy file to %q: %w", dest, err)
+	}
+	return nil
+}
+
+func targetPath(o *types.ObjectToDownload) *string {
+	return &o.ActualPath
+}
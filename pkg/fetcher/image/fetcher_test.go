@@ -0,0 +1,253 @@
+package image_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/image"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+type entry struct {
+	name     string
+	content  string
+	typeflag byte
+	linkname string
+}
+
+func layer(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0o644}
+		switch e.typeflag {
+		case tar.TypeDir:
+			hdr.Mode = 0o755
+		case tar.TypeReg:
+			hdr.Size = int64(len(e.content))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := io.WriteString(tw, e.content); err != nil {
+				t.Fatalf("failed to write content: %v", err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func file(name, content string) entry {
+	return entry{name: name, content: content, typeflag: tar.TypeReg}
+}
+
+func dir(name string) entry {
+	return entry{name: name, typeflag: tar.TypeDir}
+}
+
+func symlink(name, target string) entry {
+	return entry{name: name, typeflag: tar.TypeSymlink, linkname: target}
+}
+
+func pushImage(t *testing.T, layers ...[]byte) string {
+	t.Helper()
+
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	reference := strings.TrimPrefix(server.URL, "http://") + "/apps/demo:v1"
+
+	img := empty.Image
+	for _, l := range layers {
+		var err error
+		img, err = mutate.AppendLayers(img, static.NewLayer(l, ggcrtypes.DockerUncompressedLayer))
+		if err != nil {
+			t.Fatalf("failed to append layer: %v", err)
+		}
+	}
+
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		t.Fatalf("invalid reference: %v", err)
+	}
+	if err = remote.Write(ref, img); err != nil {
+		t.Fatalf("failed to push image: %v", err)
+	}
+	return reference
+}
+
+func fetchAll(t *testing.T, opts types.ImageOptions) (string, error) {
+	t.Helper()
+
+	f := image.NewFetcher(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	mountPath := t.TempDir()
+	obj, err := f.Fetch(context.Background(), mountPath, types.Source{Type: types.SourceTypeIMAGE, Image: &opts})
+	if err != nil {
+		return mountPath, err
+	}
+	defer func() {
+		if cerr := obj.Cleanup(context.Background()); cerr != nil {
+			t.Errorf("cleanup failed: %v", cerr)
+		}
+	}()
+
+	for _, o := range obj.Objects {
+		if err = obj.Processor(context.Background(), o); err != nil {
+			return mountPath, err
+		}
+	}
+	return mountPath, nil
+}
+
+func listFiles(t *testing.T, root string) []string {
+	t.Helper()
+
+	var files []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk %q: %v", root, err)
+	}
+	slices.Sort(files)
+	return files
+}
+
+func TestFetcher_Fetch_AppliesLayers(t *testing.T) {
+	reference := pushImage(t,
+		layer(t,
+			dir("etc/"),
+			dir("etc/app/"),
+			file("etc/app/config.yaml", "v1"),
+			file("etc/app/old.txt", "old"),
+			dir("etc/app/cache/"),
+			file("etc/app/cache/a", "a"),
+			file("usr/bin/tool", "tool"),
+		),
+		layer(t,
+			file("etc/app/.wh.old.txt", ""),
+			file("./etc/app/config.yaml", "v2"),
+			file("etc/app/cache/b", "b"),
+			file("etc/app/cache/.wh..wh..opq", ""),
+			symlink("etc/app/current", "config.yaml"),
+		),
+	)
+
+	mountPath, err := fetchAll(t, types.ImageOptions{Reference: reference, Paths: []string{"/etc/app"}})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	want := []string{"cache/b", "config.yaml", "current"}
+	if got := listFiles(t, mountPath); !slices.Equal(got, want) {
+		t.Fatalf("expected files %v, got %v", want, got)
+	}
+
+	data, err := os.ReadFile(filepath.Join(mountPath, "config.yaml"))
+	if err != nil || string(data) != "v2" {
+		t.Errorf("expected upper layer content, got %q (%v)", data, err)
+	}
+
+	link, err := os.Readlink(filepath.Join(mountPath, "current"))
+	if err != nil || link != "config.yaml" {
+		t.Errorf("expected symlink to be preserved, got %q (%v)", link, err)
+	}
+}
+
+func TestFetcher_Fetch_SymlinkEscape(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "keep.txt"), []byte("keep"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	reference := pushImage(t,
+		layer(t,
+			symlink("data/escape", outside),
+			file("data/safe.txt", "safe"),
+		),
+		layer(t,
+			file("data/escape/pwned.txt", "pwned"),
+			file("data/escape/.wh.keep.txt", ""),
+			file("data/escape/.wh..wh..opq", ""),
+		),
+	)
+
+	if _, err := fetchAll(t, types.ImageOptions{Reference: reference, Paths: []string{"data"}}); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	if got := listFiles(t, outside); !slices.Equal(got, []string{"keep.txt"}) {
+		t.Errorf("expected directory outside the image root to be untouched, got %v", got)
+	}
+}
+
+func TestFetcher_Fetch_Errors(t *testing.T) {
+	reference := pushImage(t, layer(t, file("etc/app/config.yaml", "v1")))
+
+	tests := []struct {
+		name string
+		opts types.ImageOptions
+		want string
+	}{
+		{name: "missing path", opts: types.ImageOptions{Reference: reference, Paths: []string{"opt/missing"}}, want: "not found in image"},
+		{name: "invalid platform", opts: types.ImageOptions{Reference: reference, Platform: "linux/amd64/v1/extra", Paths: []string{"etc"}}, want: "invalid platform"},
+		{name: "missing image", opts: types.ImageOptions{Reference: reference + "-missing", Paths: []string{"etc"}}, want: "failed to resolve image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetchAll(t, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFetcher_Fetch_InvalidPatternRemovesTempDir(t *testing.T) {
+	reference := pushImage(t, layer(t, file("etc/app/config.yaml", "v1")))
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	f := image.NewFetcher(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	_, err := f.Fetch(context.Background(), t.TempDir(), types.Source{
+		Type:    types.SourceTypeIMAGE,
+		Image:   &types.ImageOptions{Reference: reference, Paths: []string{"etc"}},
+		Include: []string{"[abc"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid glob pattern") {
+		t.Fatalf("expected invalid pattern error, got %v", err)
+	}
+
+	entries, err := os.ReadDir(tmp)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected the unpacked image to be removed, got %v (%v)", entries, err)
+	}
+}
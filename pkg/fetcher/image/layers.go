@@ -0,0 +1,226 @@
+package image
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// applier replays layer tarballs onto root in order, the way an overlay filesystem would, keeping only
+// entries under the selected scopes. Whiteouts are honoured everywhere so that a selected directory
+// reflects deletions made by upper layers.
+type applier struct {
+	root   string
+	scopes []string
+	logger *slog.Logger
+}
+
+func newApplier(root string, paths []string, logger *slog.Logger) *applier {
+	var scopes []string
+	for _, p := range paths {
+		scopes = append(scopes, cleanName(p))
+	}
+	return &applier{root: root, scopes: scopes, logger: logger}
+}
+
+func (a *applier) apply(r io.Reader) error {
+	tr := tar.NewReader(r)
+	written := map[string]bool{}
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read layer: %w", err)
+		}
+
+		name := cleanName(hdr.Name)
+		if name == "" {
+			continue
+		}
+
+		dir, base := filepath.Split(name)
+		switch {
+		case base == whiteoutOpaque:
+			if marker, ok := a.resolve(name); ok {
+				if err = a.clearExcept(filepath.Dir(marker), filepath.Clean(dir), written); err != nil {
+					return err
+				}
+			}
+		case strings.HasPrefix(base, whiteoutPrefix):
+			if target, ok := a.resolve(filepath.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))); ok {
+				if err = os.RemoveAll(target); err != nil {
+					return fmt.Errorf("failed to apply whiteout %q: %w", name, err)
+				}
+			}
+		case a.inScope(name):
+			if err = a.write(tr, hdr, name); err != nil {
+				return err
+			}
+			written[name] = true
+		}
+	}
+}
+
+func (a *applier) write(tr *tar.Reader, hdr *tar.Header, name string) error {
+	target, ok := a.resolve(name)
+	if !ok {
+		a.logger.Warn("skipping entry below a symlink", "entry", name)
+		return nil
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+			if err = os.Remove(target); err != nil {
+				return fmt.Errorf("failed to replace %q: %w", name, err)
+			}
+		}
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory %q: %w", name, err)
+		}
+	case tar.TypeReg:
+		if err := a.prepare(target); err != nil {
+			return err
+		}
+		fh, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, hdr.FileInfo().Mode().Perm()|0o600)
+		if err != nil {
+			return fmt.Errorf("failed to create file %q: %w", name, err)
+		}
+		_, err = io.Copy(fh, tr)
+		if cerr := fh.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write file %q: %w", name, err)
+		}
+	case tar.TypeSymlink:
+		if err := a.prepare(target); err != nil {
+			return err
+		}
+		if err := os.Symlink(hdr.Linkname, target); err != nil {
+			return fmt.Errorf("failed to create symlink %q: %w", name, err)
+		}
+	case tar.TypeLink:
+		source, ok := a.resolve(cleanName(hdr.Linkname))
+		if !ok {
+			a.logger.Warn("skipping hard link with unsafe target", "entry", name, "target", hdr.Linkname)
+			return nil
+		}
+		if _, err := os.Lstat(source); err != nil {
+			a.logger.Warn("skipping hard link to an unselected path", "entry", name, "target", hdr.Linkname)
+			return nil
+		}
+		if err := a.prepare(target); err != nil {
+			return err
+		}
+		if err := os.Link(source, target); err != nil {
+			return fmt.Errorf("failed to create hard link %q: %w", name, err)
+		}
+	default:
+		a.logger.Debug("skipping special file", "entry", name, "type", hdr.Typeflag)
+	}
+
+	return nil
+}
+
+// prepare replaces whatever a lower layer left at target.
+func (a *applier) prepare(target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", target, err)
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("failed to replace %q: %w", target, err)
+	}
+	return nil
+}
+
+// clearExcept removes the lower layer contents of dir, keeping entries already written by the current layer.
+func (a *applier) clearExcept(dir, rel string, written map[string]bool) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply opaque whiteout on %q: %w", rel, err)
+	}
+
+	for _, e := range entries {
+		childRel := filepath.Join(rel, e.Name())
+		childPath := filepath.Join(dir, e.Name())
+
+		if !writtenUnder(written, childRel) {
+			if err = os.RemoveAll(childPath); err != nil {
+				return fmt.Errorf("failed to apply opaque whiteout on %q: %w", rel, err)
+			}
+			continue
+		}
+		if e.IsDir() {
+			if err = a.clearExcept(childPath, childRel, written); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve joins name onto root, refusing names whose parent directories are symlinks so that
+// neither writes nor whiteouts can escape root.
+func (a *applier) resolve(name string) (string, bool) {
+	name = filepath.Clean(name)
+	if name == "." {
+		return a.root, true
+	}
+	if !filepath.IsLocal(name) {
+		return "", false
+	}
+
+	current := a.root
+	parts := strings.Split(name, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		current = filepath.Join(current, part)
+		if info, err := os.Lstat(current); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return "", false
+		}
+	}
+	return filepath.Join(a.root, name), true
+}
+
+func (a *applier) inScope(name string) bool {
+	for _, scope := range a.scopes {
+		if scope == "" || name == scope || strings.HasPrefix(name, scope+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func writtenUnder(written map[string]bool, rel string) bool {
+	if written[rel] {
+		return true
+	}
+	for name := range written {
+		if strings.HasPrefix(name, rel+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanName makes name relative to the image root, resolving any ".." against the root itself.
+func cleanName(name string) string {
+	name = filepath.Clean("/" + name)
+	return strings.TrimPrefix(name, "/")
+}
@@ -0,0 +1,150 @@
+package inline
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+type Fetcher struct {
+	logger *slog.Logger
+}
+
+// templateData is exposed to templated content, e.g. {{ .Env.CLUSTER_NAME }}.
+type templateData struct {
+	Env map[string]string
+}
+
+func NewFetcher(logger *slog.Logger) fetcher.Fetcher {
+	return &Fetcher{
+		logger: logger,
+	}
+}
+
+// Fetch decodes the content up front so that invalid sources fail before anything is written. The
+// content is written to the target path itself.
+func (f *Fetcher) Fetch(_ context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	content, err := decode(src.Inline.Content, src.Inline.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	if src.Inline.Template {
+		if content, err = render(content); err != nil {
+			return nil, err
+		}
+	}
+
+	objects := []types.ObjectToDownload{
+		{
+			ActualPath: string(types.SourceTypeINLINE),
+			Path:       mountPath,
+			Size:       int64(len(content)),
+		},
+	}
+
+	objects, err = utils.FilterObjectsBy(mountPath, objects, src.Include, src.Exclude, func(o *types.ObjectToDownload) *string {
+		return &o.Path
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	workers := 1
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
+			return f.write(ctx, j.Path, content)
+		},
+		Objects: objects,
+		Workers: &workers,
+	}, nil
+}
+
+func (f *Fetcher) write(ctx context.Context, targetPath string, content []byte) error {
+	f.logger.Info("writing inline content", "file", targetPath, "size", len(content))
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
+	}
+	if err := extract.WriteFile(ctx, targetPath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write file %q: %w", targetPath, err)
+	}
+	return nil
+}
+
+func decode(content string, encoding types.InlineEncoding) ([]byte, error) {
+	switch encoding {
+	case "", types.InlineEncodingText:
+		return []byte(content), nil
+	case types.InlineEncodingBase64:
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(content), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 content: %w", err)
+		}
+		return data, nil
+	case types.InlineEncodingDataURI:
+		return decodeDataURI(strings.TrimSpace(content))
+	default:
+		return nil, fmt.Errorf("unsupported inline encoding %q", encoding)
+	}
+}
+
+// decodeDataURI decodes an RFC 2397 URI of the form data:[<mediatype>][;base64],<data>. The media type
+// is ignored.
+func decodeDataURI(uri string) ([]byte, error) {
+	rest, ok := strings.CutPrefix(uri, "data:")
+	if !ok {
+		return nil, fmt.Errorf("invalid data URI: missing data: scheme")
+	}
+	meta, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid data URI: missing comma")
+	}
+
+	if strings.HasSuffix(meta, ";base64") {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 in data URI: %w", err)
+		}
+		return decoded, nil
+	}
+
+	decoded, err := url.PathUnescape(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid escaping in data URI: %w", err)
+	}
+	return []byte(decoded), nil
+}
+
+// render executes content as a text/template. Referencing an unset variable is an error rather than
+// silently producing an empty value.
+func render(content []byte) ([]byte, error) {
+	tmpl, err := template.New(string(types.SourceTypeINLINE)).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, templateData{Env: env}); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
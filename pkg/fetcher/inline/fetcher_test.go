@@ -0,0 +1,114 @@
+package inline_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/inline"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+func fetch(t *testing.T, opts types.InlineOptions) (string, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	target := filepath.Join(t.TempDir(), "config", "app.yaml")
+	f := inline.NewFetcher(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	obj, err := f.Fetch(ctx, target, types.Source{Type: types.SourceTypeINLINE, Inline: &opts})
+	if err != nil {
+		return "", err
+	}
+	if len(obj.Objects) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(obj.Objects))
+	}
+	if err = obj.Processor(ctx, obj.Objects[0]); err != nil {
+		t.Fatalf("Processor failed: %v", err)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("failed to read target: %v", err)
+	}
+	return string(data), nil
+}
+
+func TestFetcher_Fetch_Encodings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts types.InlineOptions
+		want string
+	}{
+		{name: "plain text", opts: types.InlineOptions{Content: "replicas: 3\n"}, want: "replicas: 3\n"},
+		{name: "explicit text", opts: types.InlineOptions{Content: "data: not a uri", Encoding: types.InlineEncodingText}, want: "data: not a uri"},
+		{name: "base64", opts: types.InlineOptions{Content: "aGVsbG8g\nd29ybGQ=\n", Encoding: types.InlineEncodingBase64}, want: "hello world"},
+		{name: "data uri base64", opts: types.InlineOptions{Content: "data:text/plain;base64,aGVsbG8=", Encoding: types.InlineEncodingDataURI}, want: "hello"},
+		{name: "data uri escaped", opts: types.InlineOptions{Content: "data:,a%20b%2Cc", Encoding: types.InlineEncodingDataURI}, want: "a b,c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetch(t, tt.opts)
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFetcher_Fetch_Template(t *testing.T) {
+	t.Setenv("INLINE_TEST_CLUSTER", "prod-eu")
+
+	got, err := fetch(t, types.InlineOptions{
+		Content:  "Y2x1c3Rlcjoge3sgLkVudi5JTkxJTkVfVEVTVF9DTFVTVEVSIH19Cg==",
+		Encoding: types.InlineEncodingBase64,
+		Template: true,
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if got != "cluster: prod-eu\n" {
+		t.Errorf("unexpected rendered content %q", got)
+	}
+
+	got, err = fetch(t, types.InlineOptions{Content: "{{ .Env.INLINE_TEST_CLUSTER }}"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if got != "{{ .Env.INLINE_TEST_CLUSTER }}" {
+		t.Errorf("expected content to be left as-is without template, got %q", got)
+	}
+}
+
+func TestFetcher_Fetch_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts types.InlineOptions
+		want string
+	}{
+		{name: "invalid base64", opts: types.InlineOptions{Content: "!!", Encoding: types.InlineEncodingBase64}, want: "invalid base64"},
+		{name: "missing scheme", opts: types.InlineOptions{Content: "text/plain,abc", Encoding: types.InlineEncodingDataURI}, want: "missing data: scheme"},
+		{name: "missing comma", opts: types.InlineOptions{Content: "data:abc", Encoding: types.InlineEncodingDataURI}, want: "missing comma"},
+		{name: "unknown encoding", opts: types.InlineOptions{Content: "abc", Encoding: "hex"}, want: "unsupported inline encoding"},
+		{name: "missing variable", opts: types.InlineOptions{Content: "{{ .Env.INLINE_TEST_UNSET }}", Template: true}, want: "failed to render"},
+		{name: "invalid template", opts: types.InlineOptions{Content: "{{ .Env", Template: true}, want: "invalid template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetch(t, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type ClientFactory func() (kubernetes.Interface, error)
+
+type kind string
+
+const (
+	kindConfigMap kind = "configmap"
+	kindSecret    kind = "secret"
+)
+
+type Fetcher struct {
+	clientFactory ClientFactory
+	kind          kind
+	logger        *slog.Logger
+}
+
+func NewConfigMapFetcher(clientFactory ClientFactory, logger *slog.Logger) fetcher.Fetcher {
+	return &Fetcher{
+		clientFactory: clientFactory,
+		kind:          kindConfigMap,
+		logger:        logger,
+	}
+}
+
+func NewSecretFetcher(clientFactory ClientFactory, logger *slog.Logger) fetcher.Fetcher {
+	return &Fetcher{
+		clientFactory: clientFactory,
+		kind:          kindSecret,
+		logger:        logger,
+	}
+}
+
+// NewClientFactory connects lazily, so populators without configmap or secret sources never need API access.
+// Empty arguments use the in-cluster configuration of the populator pod.
+func NewClientFactory(masterURL, kubeconfig string) ClientFactory {
+	return sync.OnceValues(func() (kubernetes.Interface, error) {
+		cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		return kubernetes.NewForConfig(cfg)
+	})
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	opts := src.ConfigMap
+	if f.kind == kindSecret {
+		opts = src.Secret
+	}
+
+	client, err := f.clientFactory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	data, err := f.read(ctx, client, *opts)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := opts.Keys
+	if len(keys) == 0 {
+		for k := range data {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+	}
+
+	var objects []types.ObjectToDownload
+	for _, key := range keys {
+		value, ok := data[key]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found in %s %s/%s", key, f.kind, opts.Namespace, opts.Name)
+		}
+		if !filepath.IsLocal(key) {
+			return nil, fmt.Errorf("key %q of %s %s/%s is not a valid file name", key, f.kind, opts.Namespace, opts.Name)
+		}
+		objects = append(objects, types.ObjectToDownload{
+			ActualPath: key,
+			Path:       filepath.Join(mountPath, key),
+			Size:       int64(len(value)),
+		})
+	}
+
+	objects, err = utils.SelectObjectsBy(mountPath, objects, src, targetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
+			return f.write(ctx, j.Path, data[j.ActualPath])
+		},
+		Objects: objects,
+	}, nil
+}
+
+func (f *Fetcher) read(ctx context.Context, client kubernetes.Interface, opts types.KubeObjectOptions) (map[string][]byte, error) {
+	data := map[string][]byte{}
+
+	switch f.kind {
+	case kindSecret:
+		secret, err := client.CoreV1().Secrets(opts.Namespace).Get(ctx, opts.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", opts.Namespace, opts.Name, err)
+		}
+		for k, v := range secret.Data {
+			data[k] = v
+		}
+	default:
+		cm, err := client.CoreV1().ConfigMaps(opts.Namespace).Get(ctx, opts.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get configmap %s/%s: %w", opts.Namespace, opts.Name, err)
+		}
+		for k, v := range cm.Data {
+			data[k] = []byte(v)
+		}
+		for k, v := range cm.BinaryData {
+			data[k] = v
+		}
+	}
+
+	return data, nil
+}
+
+func (f *Fetcher) write(ctx context.Context, targetPath string, value []byte) error {
+	f.logger.Info("writing key", "file", targetPath)
+
+	perm := os.FileMode(0o644)
+	if f.kind == kindSecret {
+		perm = 0o600
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
+	}
+	if err := extract.WriteFile(ctx, targetPath, value, perm); err != nil {
+		return fmt.Errorf("failed to write file %q: %w", targetPath, err)
+	}
+	return nil
+}
+
+func targetPath(o *types.ObjectToDownload) *string {
+	return &o.Path
+}
@@ -0,0 +1,164 @@
+package kube_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/fetcher/kube"
+	"github.com/AdamShannag/volare/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func clientFactory(objects ...runtime.Object) kube.ClientFactory {
+	client := fake.NewClientset(objects...)
+	return func() (kubernetes.Interface, error) {
+		return client, nil
+	}
+}
+
+func run(t *testing.T, f fetcher.Fetcher, src types.Source) (string, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	obj, err := f.Fetch(ctx, tmpDir, src)
+	if err != nil {
+		return "", err
+	}
+	for _, o := range obj.Objects {
+		if pErr := obj.Processor(ctx, o); pErr != nil {
+			t.Fatalf("Processor failed for %q: %v", o.ActualPath, pErr)
+		}
+	}
+	return tmpDir, nil
+}
+
+func TestConfigMapFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "team-a"},
+		Data:       map[string]string{"app.yaml": "replicas: 3", "log.conf": "level=info"},
+		BinaryData: map[string][]byte{"logo.png": {0x89, 0x50}},
+	}
+	f := kube.NewConfigMapFetcher(clientFactory(cm), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	dir, err := run(t, f, types.Source{
+		Type:      types.SourceTypeCONFIGMAP,
+		ConfigMap: &types.KubeObjectOptions{Name: "app-config", Namespace: "team-a"},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	for name, want := range map[string]string{"app.yaml": "replicas: 3", "log.conf": "level=info", "logo.png": "\x89\x50"} {
+		data, readErr := os.ReadFile(filepath.Join(dir, name))
+		if readErr != nil || string(data) != want {
+			t.Errorf("unexpected content for %q: %q (%v)", name, data, readErr)
+		}
+	}
+
+	dir, err = run(t, f, types.Source{
+		Type:      types.SourceTypeCONFIGMAP,
+		ConfigMap: &types.KubeObjectOptions{Name: "app-config", Namespace: "team-a", Keys: []string{"app.yaml"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "app.yaml" {
+		t.Errorf("expected only the selected key, got %v", entries)
+	}
+}
+
+func TestSecretFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "team-a"},
+		Data:       map[string][]byte{"password": []byte("s3cr3t"), "username": []byte("app")},
+	}
+	f := kube.NewSecretFetcher(clientFactory(secret), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	dir, err := run(t, f, types.Source{
+		Type:   types.SourceTypeSECRET,
+		Secret: &types.KubeObjectOptions{Name: "db", Namespace: "team-a", Keys: []string{"password"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "password"))
+	if err != nil {
+		t.Fatalf("expected password file: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected secret file mode 0600, got %v", info.Mode().Perm())
+	}
+	if _, err = os.Stat(filepath.Join(dir, "username")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected unselected key to be skipped, got %v", err)
+	}
+}
+
+func TestFetcher_Fetch_Errors(t *testing.T) {
+	t.Parallel()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "team-a"},
+		Data:       map[string]string{"app.yaml": "replicas: 3"},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name string
+		f    fetcher.Fetcher
+		src  types.Source
+		want string
+	}{
+		{
+			name: "missing object",
+			f:    kube.NewSecretFetcher(clientFactory(cm), logger),
+			src:  types.Source{Secret: &types.KubeObjectOptions{Name: "app-config", Namespace: "team-a"}},
+			want: "failed to get secret team-a/app-config",
+		},
+		{
+			name: "other namespace",
+			f:    kube.NewConfigMapFetcher(clientFactory(cm), logger),
+			src:  types.Source{ConfigMap: &types.KubeObjectOptions{Name: "app-config", Namespace: "team-b"}},
+			want: "not found",
+		},
+		{
+			name: "missing key",
+			f:    kube.NewConfigMapFetcher(clientFactory(cm), logger),
+			src:  types.Source{ConfigMap: &types.KubeObjectOptions{Name: "app-config", Namespace: "team-a", Keys: []string{"missing"}}},
+			want: `key "missing" not found`,
+		},
+		{
+			name: "client error",
+			f: kube.NewConfigMapFetcher(func() (kubernetes.Interface, error) {
+				return nil, errors.New("no in-cluster config")
+			}, logger),
+			src:  types.Source{ConfigMap: &types.KubeObjectOptions{Name: "app-config", Namespace: "team-a"}},
+			want: "failed to create kubernetes client",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(t, tt.f, tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+const AnnotationTitle = "org.opencontainers.image.title"
+
+type Fetcher struct {
+	logger *slog.Logger
+}
+
+func NewFetcher(logger *slog.Logger) fetcher.Fetcher {
+	return &Fetcher{
+		logger: logger,
+	}
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	opts := *src.OCI
+	if err := utils.ValidatePatterns(opts.Titles); err != nil {
+		return nil, err
+	}
+
+	ref, err := ParseReference(opts.Reference, opts.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := remote.Get(ref, RemoteOptions(ctx, opts.Username, opts.Password, opts.Token)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %w", opts.Reference, err)
+	}
+	if desc.MediaType.IsIndex() {
+		return nil, fmt.Errorf("reference %q resolves to an index, pin a manifest digest instead", opts.Reference)
+	}
+
+	manifest, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest of %q: %w", opts.Reference, err)
+	}
+	f.logger.Info("resolved reference", "reference", opts.Reference, "digest", desc.Digest.String())
+
+	var objects []types.ObjectToDownload
+	for _, layer := range manifest.Layers {
+		title := layer.Annotations[AnnotationTitle]
+		if !selectLayer(layer, title, opts) {
+			continue
+		}
+
+		fileName := title
+		if fileName == "" {
+			fileName = layer.Digest.Hex
+		}
+		if !filepath.IsLocal(fileName) {
+			return nil, fmt.Errorf("layer %s has unsafe title %q", layer.Digest, fileName)
+		}
+
+		objects = append(objects, types.ObjectToDownload{
+			ActualPath: fileName,
+			Version:    layer.Digest.String(),
+			Size:       layer.Size,
+		})
+	}
+
+	if len(objects) == 0 {
+		f.logger.Info("no layers matched", "reference", opts.Reference, "mediaTypes", opts.MediaTypes, "titles", opts.Titles)
+	}
+
+	objects, err = utils.SelectObjects(mountPath, objects, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, job types.ObjectToDownload) error {
+			return f.download(ctx, ref.Context(), mountPath, opts, job)
+		},
+		Objects: objects,
+		Workers: opts.Workers,
+	}, nil
+}
+
+// selectLayer requires every configured filter to match. Without filters, only layers with a title
+// are selected, like `oras pull`.
+func selectLayer(layer v1.Descriptor, title string, opts types.OCIOptions) bool {
+	if len(opts.MediaTypes) == 0 && len(opts.Titles) == 0 {
+		return title != ""
+	}
+	if len(opts.MediaTypes) > 0 && !slices.Contains(opts.MediaTypes, string(layer.MediaType)) {
+		return false
+	}
+	if len(opts.Titles) > 0 {
+		return utils.MatchPatterns(title, opts.Titles, nil)
+	}
+	return true
+}
+
+func (f *Fetcher) download(ctx context.Context, repo name.Repository, mountPath string, opts types.OCIOptions, file types.ObjectToDownload) error {
+	digest, err := v1.NewHash(file.Version)
+	if err != nil {
+		return fmt.Errorf("invalid digest %q: %w", file.Version, err)
+	}
+	hasher, err := v1.Hasher(digest.Algorithm)
+	if err != nil {
+		return fmt.Errorf("unsupported digest %q: %w", file.Version, err)
+	}
+
+	f.logger.Info("downloading layer", "repository", repo.String(), "digest", file.Version, "file", file.ActualPath)
+
+	layer, err := remote.Layer(repo.Digest(digest.String()), RemoteOptions(ctx, opts.Username, opts.Password, opts.Token)...)
+	if err != nil {
+		return fmt.Errorf("failed to get layer %s: %w", digest, err)
+	}
+	reader, err := layer.Compressed()
+	if err != nil {
+		return fmt.Errorf("failed to read layer %s: %w", digest, err)
+	}
+	defer func() {
+		if cerr := reader.Close(); cerr != nil {
+			f.logger.Warn("error closing layer reader", "digest", file.Version, "error", cerr)
+		}
+	}()
+
+	targetPath := utils.ResolveTargetPath(mountPath, file)
+	if err = os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
+	}
+
+	fh, err := extract.Create(ctx, targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", targetPath, err)
+	}
+	defer func() {
+		if cerr := fh.Close(); cerr != nil {
+			f.logger.Warn("error closing file", "file", targetPath, "error", cerr)
+		}
+	}()
+
+	n, err := io.Copy(io.MultiWriter(fh, hasher), reader)
+	if err == nil {
+		if got := fmt.Sprintf("%x", hasher.Sum(nil)); got != digest.Hex || n != file.Size {
+			err = fmt.Errorf("digest mismatch: expected %s (%d bytes), got %s:%s (%d bytes)", digest, file.Size, digest.Algorithm, got, n)
+		}
+	}
+	if err != nil {
+		// Files already extracted from the layer cannot be rolled back, only a decompressed file is removed.
+		extract.Abort(fh, err)
+		if !extract.Applies(ctx, targetPath) {
+			if rmErr := os.Remove(targetPath); rmErr != nil {
+				f.logger.Warn("error removing unverified file", "file", targetPath, "error", rmErr)
+			}
+		}
+		return fmt.Errorf("failed to verify layer %s for %q: %w", digest, file.ActualPath, err)
+	}
+
+	return nil
+}
+
+func ParseReference(reference string, insecure bool) (name.Reference, error) {
+	var nameOpts []name.Option
+	if insecure {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+
+	ref, err := name.ParseReference(reference, nameOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference %q: %w", reference, err)
+	}
+	return ref, nil
+}
+
+// RemoteOptions authenticates with a registry token or username and password when set, and falls
+// back to the docker config keychain otherwise.
+func RemoteOptions(ctx context.Context, username, password, token string) []remote.Option {
+	remoteOpts := []remote.Option{remote.WithContext(ctx)}
+
+	switch {
+	case token != "":
+		remoteOpts = append(remoteOpts, remote.WithAuth(authn.FromConfig(authn.AuthConfig{RegistryToken: utils.FromEnv(token)})))
+	case password != "":
+		remoteOpts = append(remoteOpts, remote.WithAuth(authn.FromConfig(authn.AuthConfig{
+			Username: utils.FromEnv(username),
+			Password: utils.FromEnv(password),
+		})))
+	default:
+		remoteOpts = append(remoteOpts, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	}
+
+	return remoteOpts
+}
@@ -0,0 +1,306 @@
+package oci_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher/oci"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+type artifactLayer struct {
+	title     string
+	mediaType string
+	content   string
+}
+
+// pushArtifact pushes an ORAS style artifact and returns its manifest digest.
+func pushArtifact(t *testing.T, reference string, layers []artifactLayer) v1.Hash {
+	t.Helper()
+
+	img := mutate.MediaType(empty.Image, ggcrtypes.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, "application/vnd.volare.test.config.v1+json")
+	for _, l := range layers {
+		var annotations map[string]string
+		if l.title != "" {
+			annotations = map[string]string{oci.AnnotationTitle: l.title}
+		}
+
+		var err error
+		img, err = mutate.Append(img, mutate.Addendum{
+			Layer:       static.NewLayer([]byte(l.content), ggcrtypes.MediaType(l.mediaType)),
+			Annotations: annotations,
+		})
+		if err != nil {
+			t.Fatalf("failed to append layer: %v", err)
+		}
+	}
+
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		t.Fatalf("invalid reference: %v", err)
+	}
+	if err = remote.Write(ref, img); err != nil {
+		t.Fatalf("failed to push artifact: %v", err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to compute digest: %v", err)
+	}
+	return digest
+}
+
+func layerDigest(t *testing.T, content string) v1.Hash {
+	t.Helper()
+
+	digest, err := static.NewLayer([]byte(content), "").Digest()
+	if err != nil {
+		t.Fatalf("failed to compute digest: %v", err)
+	}
+	return digest
+}
+
+func newRegistry(t *testing.T, handler func(next http.Handler) http.Handler) string {
+	t.Helper()
+
+	var h http.Handler = registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	if handler != nil {
+		h = handler(h)
+	}
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func fetchAll(t *testing.T, opts types.OCIOptions) (string, error) {
+	t.Helper()
+
+	f := oci.NewFetcher(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	mountPath := t.TempDir()
+	obj, err := f.Fetch(context.Background(), mountPath, types.Source{Type: types.SourceTypeOCI, OCI: &opts})
+	if err != nil {
+		return mountPath, err
+	}
+	for _, o := range obj.Objects {
+		if err = obj.Processor(context.Background(), o); err != nil {
+			return mountPath, err
+		}
+	}
+	return mountPath, nil
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk %q: %v", dir, err)
+	}
+	return files
+}
+
+var testLayers = []artifactLayer{
+	{title: "model.bin", mediaType: "application/vnd.volare.model", content: "weights"},
+	{title: "configs/app.json", mediaType: "application/json", content: `{"a":1}`},
+	{mediaType: "application/vnd.volare.untitled", content: "untitled"},
+}
+
+func TestFetcher_Fetch_Selection(t *testing.T) {
+	host := newRegistry(t, nil)
+	reference := host + "/models/demo:v1"
+	digest := pushArtifact(t, reference, testLayers)
+
+	tests := []struct {
+		name string
+		opts types.OCIOptions
+		want []string
+	}{
+		{
+			name: "titled layers by default",
+			opts: types.OCIOptions{Reference: reference},
+			want: []string{"configs/app.json", "model.bin"},
+		},
+		{
+			name: "by digest",
+			opts: types.OCIOptions{Reference: host + "/models/demo@" + digest.String()},
+			want: []string{"configs/app.json", "model.bin"},
+		},
+		{
+			name: "by media type",
+			opts: types.OCIOptions{Reference: reference, MediaTypes: []string{"application/vnd.volare.model"}},
+			want: []string{"model.bin"},
+		},
+		{
+			name: "by title pattern",
+			opts: types.OCIOptions{Reference: reference, Titles: []string{"configs/*.json"}},
+			want: []string{"configs/app.json"},
+		},
+		{
+			name: "by doublestar title pattern",
+			opts: types.OCIOptions{Reference: reference, Titles: []string{"**/*.json"}},
+			want: []string{"configs/app.json"},
+		},
+		{
+			name: "untitled layer named by digest",
+			opts: types.OCIOptions{Reference: reference, MediaTypes: []string{"application/vnd.volare.untitled"}},
+			want: []string{layerDigest(t, "untitled").Hex},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mountPath, err := fetchAll(t, tt.opts)
+			if err != nil {
+				t.Fatalf("fetch failed: %v", err)
+			}
+
+			got := listFiles(t, mountPath)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected files %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFetcher_Fetch_Content(t *testing.T) {
+	host := newRegistry(t, nil)
+	reference := host + "/models/demo:v1"
+	pushArtifact(t, reference, testLayers)
+
+	mountPath, err := fetchAll(t, types.OCIOptions{Reference: reference})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(mountPath, "model.bin"))
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(data) != "weights" {
+		t.Errorf("unexpected content %q", data)
+	}
+}
+
+func TestFetcher_Fetch_DigestMismatch(t *testing.T) {
+	tampered := layerDigest(t, "weights")
+	host := newRegistry(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/blobs/"+tampered.String()) {
+				_, _ = io.WriteString(w, "tampere")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	reference := host + "/models/demo:v1"
+	pushArtifact(t, reference, testLayers)
+
+	mountPath, err := fetchAll(t, types.OCIOptions{Reference: reference, Titles: []string{"model.bin"}})
+	if err == nil {
+		t.Fatal("expected digest verification to fail")
+	}
+	if _, statErr := os.Stat(filepath.Join(mountPath, "model.bin")); !os.IsNotExist(statErr) {
+		t.Errorf("expected unverified file to be removed, got %v", statErr)
+	}
+}
+
+func TestFetcher_Fetch_DigestMismatchDecompress(t *testing.T) {
+	gzipped := func(content string) string {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = io.WriteString(gz, content)
+		_ = gz.Close()
+		return buf.String()
+	}
+
+	original := gzipped("weights")
+	tampered := layerDigest(t, original)
+	host := newRegistry(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/blobs/"+tampered.String()) {
+				_, _ = io.WriteString(w, gzipped("tampered"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	reference := host + "/models/demo:v1"
+	pushArtifact(t, reference, []artifactLayer{{title: "model.bin.gz", mediaType: "application/gzip", content: original}})
+
+	src := types.Source{Type: types.SourceTypeOCI, Decompress: true, OCI: &types.OCIOptions{Reference: reference}}
+	mountPath := t.TempDir()
+	obj, err := oci.NewFetcher(slog.New(slog.NewTextHandler(io.Discard, nil))).Fetch(context.Background(), mountPath, src)
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	if err = extract.Processor(src, obj.Processor)(context.Background(), obj.Objects[0]); err == nil || !strings.Contains(err.Error(), "failed to verify layer") {
+		t.Fatalf("expected digest verification to fail, got %v", err)
+	}
+	if files := listFiles(t, mountPath); len(files) != 0 {
+		t.Errorf("expected the unverified layer not to be decompressed, got %v", files)
+	}
+}
+
+func TestFetcher_Fetch_Errors(t *testing.T) {
+	host := newRegistry(t, nil)
+	pushArtifact(t, host+"/models/unsafe:v1", []artifactLayer{{title: "../escape.txt", mediaType: "text/plain", content: "x"}})
+
+	idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: empty.Image})
+	ref, err := name.ParseReference(host + "/models/index:v1")
+	if err != nil {
+		t.Fatalf("invalid reference: %v", err)
+	}
+	if err = remote.WriteIndex(ref, idx); err != nil {
+		t.Fatalf("failed to push index: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts types.OCIOptions
+		want string
+	}{
+		{name: "invalid reference", opts: types.OCIOptions{Reference: "Not A Reference"}, want: "invalid reference"},
+		{name: "missing tag", opts: types.OCIOptions{Reference: host + "/models/missing:v1"}, want: "failed to resolve"},
+		{name: "index", opts: types.OCIOptions{Reference: host + "/models/index:v1"}, want: "resolves to an index"},
+		{name: "unsafe title", opts: types.OCIOptions{Reference: host + "/models/unsafe:v1"}, want: "unsafe title"},
+		{name: "invalid title pattern", opts: types.OCIOptions{Reference: host + "/models/unsafe:v1", Titles: []string{"[abc"}}, want: "invalid glob pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetchAll(t, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package s3
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// NewCredentials returns the static keys when no chain is configured; otherwise the first provider
+// in the chain that yields keys wins. Files referenced by the chain are relative to resourcesDir.
+func NewCredentials(opts types.S3Options, resourcesDir string) (*credentials.Credentials, error) {
+	if len(opts.Credentials) == 0 {
+		return credentials.NewStaticV4(utils.FromEnv(opts.AccessKeyID), utils.FromEnv(opts.SecretAccessKey), utils.FromEnv(opts.SessionToken)), nil
+	}
+
+	var providers []credentials.Provider
+	for _, p := range opts.Credentials {
+		switch p.Type {
+		case types.S3CredentialsEnv:
+			providers = append(providers, &credentials.EnvAWS{}, &credentials.EnvMinio{})
+		case types.S3CredentialsFile:
+			providers = append(providers, &credentials.FileAWSCredentials{
+				Filename: resolveFile(resourcesDir, p.File),
+				Profile:  p.Profile,
+			})
+		case types.S3CredentialsWebIdentity:
+			providers = append(providers, newWebIdentity(opts, p, resourcesDir))
+		case types.S3CredentialsIAM:
+			providers = append(providers, &credentials.IAM{
+				Endpoint: p.Endpoint,
+				Region:   opts.Region,
+			})
+		case types.S3CredentialsStatic:
+			providers = append(providers, &credentials.Static{
+				Value: credentials.Value{
+					AccessKeyID:     utils.FromEnv(opts.AccessKeyID),
+					SecretAccessKey: utils.FromEnv(opts.SecretAccessKey),
+					SessionToken:    utils.FromEnv(opts.SessionToken),
+					SignerType:      credentials.SignatureV4,
+				},
+			})
+		default:
+			return nil, fmt.Errorf("unsupported s3 credentials provider %q", p.Type)
+		}
+	}
+
+	return credentials.NewChainCredentials(providers), nil
+}
+
+// newWebIdentity falls back to the variables injected by EKS (IRSA) for the token file and role.
+func newWebIdentity(opts types.S3Options, p types.S3CredentialsProvider, resourcesDir string) *credentials.STSWebIdentity {
+	tokenFile := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if p.TokenFile != "" {
+		tokenFile = resolveFile(resourcesDir, p.TokenFile)
+	}
+
+	roleARN := os.Getenv("AWS_ROLE_ARN")
+	if p.RoleARN != "" {
+		roleARN = utils.FromEnv(p.RoleARN)
+	}
+
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = stsEndpoint(opts.Region)
+	}
+
+	return &credentials.STSWebIdentity{
+		STSEndpoint: endpoint,
+		RoleARN:     roleARN,
+		GetWebIDTokenExpiry: func() (*credentials.WebIdentityToken, error) {
+			if tokenFile == "" {
+				return nil, fmt.Errorf("no web identity token file configured")
+			}
+			token, err := os.ReadFile(tokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read web identity token: %w", err)
+			}
+			return &credentials.WebIdentityToken{Token: strings.TrimSpace(string(token))}, nil
+		},
+	}
+}
+
+func stsEndpoint(region string) string {
+	switch {
+	case region == "":
+		return credentials.DefaultSTSRoleEndpoint
+	case strings.HasPrefix(region, "cn-"):
+		return "https://sts." + region + ".amazonaws.com.cn"
+	default:
+		return "https://sts." + region + ".amazonaws.com"
+	}
+}
+
+func resolveFile(resourcesDir, file string) string {
+	if file == "" {
+		return ""
+	}
+	return filepath.Join(resourcesDir, file)
+}
@@ -0,0 +1,159 @@
+package s3_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/s3"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+func TestNewCredentials_StaticByDefault(t *testing.T) {
+	t.Setenv("S3_TEST_SECRET", "resolved-secret")
+
+	creds, err := s3.NewCredentials(types.S3Options{
+		AccessKeyID:     "access",
+		SecretAccessKey: "S3_TEST_SECRET",
+	}, t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	v, err := creds.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if v.AccessKeyID != "access" || v.SecretAccessKey != "resolved-secret" {
+		t.Errorf("unexpected credentials: %+v", v)
+	}
+}
+
+func TestNewCredentials_ChainSkipsEmptyProviders(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_ACCESS_KEY", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_SECRET_KEY", "")
+	t.Setenv("MINIO_ROOT_USER", "")
+	t.Setenv("MINIO_ACCESS_KEY", "")
+	t.Setenv("MINIO_ROOT_PASSWORD", "")
+	t.Setenv("MINIO_SECRET_KEY", "")
+
+	resources := t.TempDir()
+	credsFile := filepath.Join(resources, "aws", "credentials")
+	if err := os.MkdirAll(filepath.Dir(credsFile), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "[default]\naws_access_key_id = default-key\naws_secret_access_key = default-secret\n\n" +
+		"[reader]\naws_access_key_id = reader-key\naws_secret_access_key = reader-secret\n"
+	if err := os.WriteFile(credsFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := s3.NewCredentials(types.S3Options{
+		AccessKeyID:     "static-key",
+		SecretAccessKey: "static-secret",
+		Credentials: []types.S3CredentialsProvider{
+			{Type: types.S3CredentialsEnv},
+			{Type: types.S3CredentialsFile, File: "aws/credentials", Profile: "reader"},
+			{Type: types.S3CredentialsStatic},
+		},
+	}, resources)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	v, err := creds.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if v.AccessKeyID != "reader-key" || v.SecretAccessKey != "reader-secret" {
+		t.Errorf("expected credentials from the shared file, got %+v", v)
+	}
+}
+
+func TestNewCredentials_Env(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "env-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+
+	creds, err := s3.NewCredentials(types.S3Options{
+		Credentials: []types.S3CredentialsProvider{{Type: types.S3CredentialsEnv}},
+	}, t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	v, err := creds.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if v.AccessKeyID != "env-key" || v.SecretAccessKey != "env-secret" {
+		t.Errorf("unexpected credentials: %+v", v)
+	}
+}
+
+func TestNewCredentials_WebIdentity(t *testing.T) {
+	const response = `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>sts-key</AccessKeyId>
+      <SecretAccessKey>sts-secret</SecretAccessKey>
+      <SessionToken>sts-token</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`
+
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("WebIdentityToken"); got != "projected-token" {
+			t.Errorf("unexpected token %q", got)
+		}
+		if got := r.PostForm.Get("RoleArn"); got != "arn:aws:iam::123456789012:role/volare" {
+			t.Errorf("unexpected role %q", got)
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	defer sts.Close()
+
+	resources := t.TempDir()
+	if err := os.WriteFile(filepath.Join(resources, "token"), []byte("projected-token\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := s3.NewCredentials(types.S3Options{
+		Credentials: []types.S3CredentialsProvider{{
+			Type:      types.S3CredentialsWebIdentity,
+			TokenFile: "token",
+			RoleARN:   "arn:aws:iam::123456789012:role/volare",
+			Endpoint:  sts.URL,
+		}},
+	}, resources)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	v, err := creds.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if v.AccessKeyID != "sts-key" || v.SecretAccessKey != "sts-secret" || v.SessionToken != "sts-token" {
+		t.Errorf("unexpected credentials: %+v", v)
+	}
+}
+
+func TestNewCredentials_UnsupportedProvider(t *testing.T) {
+	t.Parallel()
+
+	_, err := s3.NewCredentials(types.S3Options{
+		Credentials: []types.S3CredentialsProvider{{Type: "vault"}},
+	}, t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "unsupported s3 credentials provider") {
+		t.Fatalf("expected unsupported provider error, got %v", err)
+	}
+}
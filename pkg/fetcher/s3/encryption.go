@@ -0,0 +1,58 @@
+package s3
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+const sseCustomerKeySize = 32
+
+// NewServerSideEncryption returns the SSE-C key used to read objects, or nil when objects are
+// stored unencrypted or with SSE-S3/SSE-KMS, which the server decrypts transparently.
+// The key is either base64 encoded in CustomerKey or read from CustomerKeyFile under resourcesDir,
+// which may hold the raw 32 bytes or their base64 encoding.
+func NewServerSideEncryption(opts types.S3Options, resourcesDir string) (encrypt.ServerSide, error) {
+	if opts.Encryption == nil {
+		return nil, nil
+	}
+
+	var key []byte
+	switch {
+	case opts.Encryption.CustomerKeyFile != "":
+		data, err := os.ReadFile(filepath.Join(resourcesDir, opts.Encryption.CustomerKeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read customer key file: %w", err)
+		}
+		if len(data) == sseCustomerKeySize {
+			key = data
+			break
+		}
+		if key, err = decodeKey(string(data)); err != nil {
+			return nil, err
+		}
+	case opts.Encryption.CustomerKey != "":
+		var err error
+		if key, err = decodeKey(utils.FromEnv(opts.Encryption.CustomerKey)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+
+	return encrypt.NewSSEC(key)
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode customer key: %w", err)
+	}
+	return key, nil
+}
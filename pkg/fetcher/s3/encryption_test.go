@@ -0,0 +1,148 @@
+package s3_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/fetcher/s3"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+// newSSECServer is a minimal S3 stand-in serving a single SSE-C encrypted object.
+// Like S3 and MinIO, it rejects reads that do not present the matching customer key.
+func newSSECServer(t *testing.T, bucket, key, content string, customerKey []byte) *httptest.Server {
+	t.Helper()
+
+	keyMD5 := md5.Sum(customerKey)
+	expectedKey := base64.StdEncoding.EncodeToString(customerKey)
+	expectedMD5 := base64.StdEncoding.EncodeToString(keyMD5[:])
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/"+bucket+"/" && r.URL.Query().Get("list-type") == "2":
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>%s</Name><Prefix></Prefix><KeyCount>1</KeyCount><MaxKeys>1000</MaxKeys><IsTruncated>false</IsTruncated>
+  <Contents><Key>%s</Key><LastModified>2025-01-01T00:00:00.000Z</LastModified><ETag>"etag"</ETag><Size>%d</Size><StorageClass>STANDARD</StorageClass></Contents>
+</ListBucketResult>`, bucket, key, len(content))
+		case r.Method == http.MethodGet && r.URL.Path == "/"+bucket+"/"+key:
+			if r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm") != "AES256" ||
+				r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key") != expectedKey ||
+				r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5") != expectedMD5 {
+				w.Header().Set("Content-Type", "application/xml")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>InvalidRequest</Code><Message>The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.</Message></Error>`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+			w.Header().Set("ETag", `"etag"`)
+			w.Header().Set("Last-Modified", "Wed, 01 Jan 2025 00:00:00 GMT")
+			_, _ = w.Write([]byte(content))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.String())
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestFetcher_SSECustomerKey(t *testing.T) {
+	customerKey := []byte(strings.Repeat("k", 32))
+	server := newSSECServer(t, "bucket", "secret.txt", "top secret", customerKey)
+	defer server.Close()
+
+	resources := t.TempDir()
+	if err := os.WriteFile(filepath.Join(resources, "sse.key"), customerKey, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("S3_TEST_SSE_KEY", base64.StdEncoding.EncodeToString(customerKey))
+
+	tests := []struct {
+		name       string
+		encryption *types.S3EncryptionOptions
+		wantErr    string
+	}{
+		{name: "key from env", encryption: &types.S3EncryptionOptions{CustomerKey: "S3_TEST_SSE_KEY"}},
+		{name: "key from resources file", encryption: &types.S3EncryptionOptions{CustomerKeyFile: "sse.key"}},
+		{name: "missing key", encryption: nil, wantErr: "Server Side Encryption"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := s3.NewFetcher(s3.MinioClientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)), s3.WithResourcesDir(resources))
+
+			tmpDir := t.TempDir()
+			obj, err := fetcher.Fetch(context.Background(), tmpDir, types.Source{
+				S3: &types.S3Options{
+					Endpoint:        server.Listener.Addr().String(),
+					Region:          "us-east-1",
+					Bucket:          "bucket",
+					AccessKeyID:     "access",
+					SecretAccessKey: "secret",
+					Paths:           []string{""},
+					Encryption:      tt.encryption,
+				},
+			})
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			if len(obj.Objects) != 1 {
+				t.Fatalf("expected 1 object, got %d", len(obj.Objects))
+			}
+
+			err = obj.Processor(context.Background(), obj.Objects[0])
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Processor failed: %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(tmpDir, "secret.txt"))
+			if err != nil {
+				t.Fatalf("failed to read downloaded file: %v", err)
+			}
+			if string(data) != "top secret" {
+				t.Errorf("unexpected content %q", data)
+			}
+		})
+	}
+}
+
+func TestNewServerSideEncryption_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr string
+	}{
+		{name: "not base64", key: "!!!", wantErr: "failed to decode customer key"},
+		{name: "wrong length", key: base64.StdEncoding.EncodeToString([]byte("short")), wantErr: "256 bit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s3.NewServerSideEncryption(types.S3Options{
+				Encryption: &types.S3EncryptionOptions{CustomerKey: tt.key},
+			}, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
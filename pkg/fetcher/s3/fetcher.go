@@ -2,20 +2,27 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/AdamShannag/volare/pkg/downloader"
+	"github.com/AdamShannag/volare/pkg/extract"
 	"github.com/AdamShannag/volare/pkg/fetcher"
 	"github.com/AdamShannag/volare/pkg/types"
 	"github.com/AdamShannag/volare/pkg/utils"
 	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 )
 
+var ErrObjectChanged = errors.New("s3: object changed since listing")
+
 type Client interface {
 	ListObjects(ctx context.Context, bucket string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo
 	GetObject(ctx context.Context, bucket, object string, opts minio.GetObjectOptions) (io.ReadCloser, error)
@@ -23,36 +30,72 @@ type Client interface {
 
 type ClientFactory func(opts types.S3Options) (Client, error)
 
+type Option func(*Fetcher)
+
 type Fetcher struct {
 	clientFactory ClientFactory
 	logger        *slog.Logger
+	resourcesDir  string
+}
+
+func WithResourcesDir(dir string) Option {
+	return func(f *Fetcher) {
+		f.resourcesDir = dir
+	}
 }
 
-func NewFetcher(factory ClientFactory, logger *slog.Logger) fetcher.Fetcher {
-	return &Fetcher{clientFactory: factory, logger: logger}
+func NewFetcher(factory ClientFactory, logger *slog.Logger, opts ...Option) fetcher.Fetcher {
+	f := &Fetcher{
+		clientFactory: factory,
+		logger:        logger,
+		resourcesDir:  types.ResourcesDir,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
 }
 
 func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	sse, err := NewServerSideEncryption(*src.S3, f.resourcesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load s3 encryption key: %w", err)
+	}
+
 	client, err := f.clientFactory(*src.S3)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create s3 client: %w", err)
 	}
 
 	var allObjects []types.ObjectToDownload
+	etags := map[string]string{}
 	for _, p := range src.S3.Paths {
-		objectCh := client.ListObjects(ctx, src.S3.Bucket, minio.ListObjectsOptions{
-			Prefix:    strings.TrimLeft(p, "/"),
-			Recursive: true,
-		})
+		key := strings.TrimLeft(p, "/")
+		if versionID, ok := src.S3.VersionIDs[key]; ok {
+			allObjects = append(allObjects, types.ObjectToDownload{ActualPath: key, Path: p, Version: versionID})
+			continue
+		}
 
-		for object := range objectCh {
-			if object.Err != nil {
-				return nil, fmt.Errorf("failed to list objects: %w", object.Err)
-			}
+		objects, listErr := f.list(ctx, client, *src.S3, key)
+		if listErr != nil {
+			return nil, listErr
+		}
+
+		for _, object := range objects {
 			if strings.HasSuffix(object.Key, "/") {
 				continue
 			}
-			allObjects = append(allObjects, types.ObjectToDownload{ActualPath: object.Key, Path: p})
+			version, size := object.VersionID, object.Size
+			// The listed size belongs to another version, so a pinned key is read as a single stream like
+			// the keys pinned above.
+			if versionID, ok := src.S3.VersionIDs[object.Key]; ok && versionID != version {
+				version, size = versionID, 0
+			}
+			if version == "" && object.ETag != "" {
+				etags[object.Key] = object.ETag
+			}
+			allObjects = append(allObjects, types.ObjectToDownload{ActualPath: object.Key, Path: p, Version: version, Size: size})
 		}
 	}
 
@@ -60,19 +103,97 @@ func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source)
 		f.logger.Info("no files found", "bucket", src.S3.Bucket, "paths", src.S3.Paths)
 	}
 
+	allObjects, err = utils.SelectObjects(mountPath, allObjects, src)
+	if err != nil {
+		return nil, err
+	}
+
 	return &fetcher.Object{
 		Processor: func(ctx context.Context, job types.ObjectToDownload) error {
-			return f.download(ctx, client, mountPath, src.S3.Bucket, job)
+			return f.download(ctx, client, mountPath, *src.S3, sse, etags[job.ActualPath], job)
 		},
 		Objects: allObjects,
 		Workers: src.S3.Workers,
 	}, nil
 }
 
-func (f *Fetcher) download(ctx context.Context, client Client, mountPath, bucket string, file types.ObjectToDownload) error {
-	f.logger.Info("downloading file", "bucket", bucket, "key", file.ActualPath)
+func (f *Fetcher) list(ctx context.Context, client Client, opts types.S3Options, prefix string) ([]minio.ObjectInfo, error) {
+	objectCh := client.ListObjects(ctx, opts.Bucket, minio.ListObjectsOptions{
+		Prefix:       prefix,
+		Recursive:    true,
+		WithVersions: opts.AsOf != nil,
+	})
 
-	reader, err := client.GetObject(ctx, bucket, file.ActualPath, minio.GetObjectOptions{})
+	var objects []minio.ObjectInfo
+	for object := range objectCh {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", object.Err)
+		}
+		objects = append(objects, object)
+	}
+
+	if opts.AsOf == nil {
+		return objects, nil
+	}
+
+	return versionsAsOf(objects, opts.AsOf.Time), nil
+}
+
+// versionsAsOf keeps, for every key, the newest version written at or before asOf.
+// Keys whose newest such version is a delete marker did not exist at that time and are dropped.
+func versionsAsOf(versions []minio.ObjectInfo, asOf time.Time) []minio.ObjectInfo {
+	newest := make(map[string]minio.ObjectInfo)
+	var keys []string
+	for _, v := range versions {
+		if v.LastModified.After(asOf) {
+			continue
+		}
+		current, ok := newest[v.Key]
+		if !ok {
+			keys = append(keys, v.Key)
+		}
+		if !ok || v.LastModified.After(current.LastModified) {
+			newest[v.Key] = v
+		}
+	}
+
+	var objects []minio.ObjectInfo
+	for _, key := range keys {
+		if v := newest[key]; !v.IsDeleteMarker {
+			objects = append(objects, v)
+		}
+	}
+	return objects
+}
+
+func (f *Fetcher) download(ctx context.Context, client Client, mountPath string, opts types.S3Options, sse encrypt.ServerSide, etag string, file types.ObjectToDownload) error {
+	bucket := opts.Bucket
+	targetPath := utils.ResolveTargetPath(mountPath, file)
+
+	if downloader.ShouldDownloadRanges(opts.Ranged, file.Size) && !extract.Applies(ctx, targetPath) {
+		f.logger.Info("downloading file in ranges", "bucket", bucket, "key", file.ActualPath, "version", file.Version, "size", file.Size)
+		return downloader.DownloadRanges(ctx, *opts.Ranged, file.Size, targetPath, func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			getOpts := minio.GetObjectOptions{VersionID: file.Version, ServerSideEncryption: sse}
+			if err := getOpts.SetRange(offset, offset+length-1); err != nil {
+				return nil, err
+			}
+			// Unversioned ranges are pinned to the listed ETag so they cannot mix two revisions of the object.
+			if etag != "" {
+				if err := getOpts.SetMatchETag(etag); err != nil {
+					return nil, err
+				}
+			}
+			reader, err := client.GetObject(ctx, bucket, file.ActualPath, getOpts)
+			if err != nil {
+				return nil, readError(file.ActualPath, err)
+			}
+			return &rangeReader{ReadCloser: reader, key: file.ActualPath}, nil
+		})
+	}
+
+	f.logger.Info("downloading file", "bucket", bucket, "key", file.ActualPath, "version", file.Version)
+
+	reader, err := client.GetObject(ctx, bucket, file.ActualPath, minio.GetObjectOptions{VersionID: file.Version, ServerSideEncryption: sse})
 	if err != nil {
 		return fmt.Errorf("failed to get object %q: %w", file.ActualPath, err)
 	}
@@ -82,12 +203,11 @@ func (f *Fetcher) download(ctx context.Context, client Client, mountPath, bucket
 		}
 	}()
 
-	targetPath := utils.ResolveTargetPath(mountPath, file)
 	if err = os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
 		return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
 	}
 
-	fh, err := os.Create(targetPath)
+	fh, err := extract.Create(ctx, targetPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file %q: %w", targetPath, err)
 	}
@@ -98,6 +218,7 @@ func (f *Fetcher) download(ctx context.Context, client Client, mountPath, bucket
 	}()
 
 	if _, err = io.Copy(fh, reader); err != nil {
+		extract.Abort(fh, err)
 		return fmt.Errorf("failed to copy content to %q: %w", targetPath, err)
 	}
 
@@ -105,8 +226,13 @@ func (f *Fetcher) download(ctx context.Context, client Client, mountPath, bucket
 }
 
 func MinioClientFactory(opts types.S3Options) (Client, error) {
+	creds, err := NewCredentials(opts, types.ResourcesDir)
+	if err != nil {
+		return nil, err
+	}
+
 	c, err := minio.New(opts.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(utils.FromEnv(opts.AccessKeyID), utils.FromEnv(opts.SecretAccessKey), utils.FromEnv(opts.SessionToken)),
+		Creds:  creds,
 		Secure: opts.Secure,
 		Region: opts.Region,
 	})
@@ -115,3 +241,26 @@ func MinioClientFactory(opts types.S3Options) (Client, error) {
 	}
 	return &minioAdapter{client: c}, nil
 }
+
+// rangeReader maps errors of lazily issued requests through readError.
+type rangeReader struct {
+	io.ReadCloser
+	key string
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = readError(r.key, err)
+	}
+	return n, err
+}
+
+// readError reports a failed ETag precondition as ErrObjectChanged.
+func readError(key string, err error) error {
+	resp := minio.ToErrorResponse(err)
+	if resp.StatusCode == http.StatusPreconditionFailed || resp.Code == "PreconditionFailed" {
+		return fmt.Errorf("%w: %q", ErrObjectChanged, key)
+	}
+	return err
+}
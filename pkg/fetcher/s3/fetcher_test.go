@@ -1,18 +1,30 @@
 package s3_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"testing/iotest"
+	"time"
 
+	"github.com/AdamShannag/volare/pkg/extract"
 	"github.com/AdamShannag/volare/pkg/fetcher/s3"
 	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
 	"github.com/minio/minio-go/v7"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type mockClient struct {
@@ -149,3 +161,406 @@ func TestFetcher_FetchAndProcess_DownloadError(t *testing.T) {
 		t.Fatalf("expected download error, got %v", err)
 	}
 }
+
+func TestFetcher_Fetch_AsOf(t *testing.T) {
+	t.Parallel()
+
+	asOf := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	versions := []minio.ObjectInfo{
+		{Key: "data/a.txt", VersionID: "a3", LastModified: asOf.Add(time.Hour)},
+		{Key: "data/a.txt", VersionID: "a2", LastModified: asOf.Add(-time.Hour)},
+		{Key: "data/a.txt", VersionID: "a1", LastModified: asOf.Add(-48 * time.Hour)},
+		{Key: "data/b.txt", VersionID: "b2", LastModified: asOf.Add(-time.Minute), IsDeleteMarker: true},
+		{Key: "data/b.txt", VersionID: "b1", LastModified: asOf.Add(-time.Hour)},
+		{Key: "data/c.txt", VersionID: "c1", LastModified: asOf.Add(time.Minute)},
+	}
+
+	var gotVersions sync.Map
+	mock := &mockClient{
+		listObjectsFunc: func(_ context.Context, _ string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			if !opts.WithVersions {
+				t.Error("expected versions to be listed")
+			}
+			ch := make(chan minio.ObjectInfo, len(versions))
+			for _, v := range versions {
+				ch <- v
+			}
+			close(ch)
+			return ch
+		},
+		getObjectFunc: func(_ context.Context, _, object string, opts minio.GetObjectOptions) (io.ReadCloser, error) {
+			gotVersions.Store(object, opts.VersionID)
+			return io.NopCloser(strings.NewReader("data")), nil
+		},
+	}
+
+	fetcher := s3.NewFetcher(func(opts types.S3Options) (s3.Client, error) {
+		return mock, nil
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	obj, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{
+		S3: &types.S3Options{
+			Bucket: "bucket",
+			Paths:  []string{"data"},
+			AsOf:   &metav1.Time{Time: asOf},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(obj.Objects) != 1 {
+		t.Fatalf("expected 1 object, got %+v", obj.Objects)
+	}
+	if err = obj.Processor(context.Background(), obj.Objects[0]); err != nil {
+		t.Fatalf("Processor failed: %v", err)
+	}
+
+	if v, _ := gotVersions.Load("data/a.txt"); v != "a2" {
+		t.Errorf("expected version a2 of data/a.txt, got %v", v)
+	}
+}
+
+func TestFetcher_Fetch_VersionIDs(t *testing.T) {
+	t.Parallel()
+
+	var gotVersions sync.Map
+	mock := &mockClient{
+		listObjectsFunc: func(_ context.Context, _ string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			if opts.Prefix == "config.yaml" {
+				t.Error("pinned object should not be listed")
+			}
+			ch := make(chan minio.ObjectInfo, 2)
+			ch <- minio.ObjectInfo{Key: "models/a.bin"}
+			ch <- minio.ObjectInfo{Key: "models/b.bin"}
+			close(ch)
+			return ch
+		},
+		getObjectFunc: func(_ context.Context, _, object string, opts minio.GetObjectOptions) (io.ReadCloser, error) {
+			gotVersions.Store(object, opts.VersionID)
+			return io.NopCloser(strings.NewReader("data")), nil
+		},
+	}
+
+	fetcher := s3.NewFetcher(func(opts types.S3Options) (s3.Client, error) {
+		return mock, nil
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	obj, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{
+		S3: &types.S3Options{
+			Bucket: "bucket",
+			Paths:  []string{"/config.yaml", "models/"},
+			VersionIDs: map[string]string{
+				"config.yaml":  "v-config",
+				"models/b.bin": "v-b",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, job := range obj.Objects {
+		if err = obj.Processor(context.Background(), job); err != nil {
+			t.Fatalf("Processor failed: %v", err)
+		}
+	}
+
+	expected := map[string]string{"config.yaml": "v-config", "models/a.bin": "", "models/b.bin": "v-b"}
+	for key, want := range expected {
+		if got, _ := gotVersions.Load(key); got != want {
+			t.Errorf("expected version %q for %s, got %v", want, key, got)
+		}
+	}
+}
+
+func TestFetcher_Processor_PinnedVersionLargerThanListed(t *testing.T) {
+	t.Parallel()
+
+	latest := strings.Repeat("latest-", 20)
+	pinned := strings.Repeat("pinned-version-", 20)
+	mock := &mockClient{
+		listObjectsFunc: func(_ context.Context, _ string, _ minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			ch := make(chan minio.ObjectInfo, 1)
+			ch <- minio.ObjectInfo{Key: "models/large.bin", Size: int64(len(latest)), VersionID: "v-latest", ETag: "latest"}
+			close(ch)
+			return ch
+		},
+		getObjectFunc: func(_ context.Context, _, _ string, opts minio.GetObjectOptions) (io.ReadCloser, error) {
+			if header := opts.Header().Get("Range"); header != "" {
+				return nil, fmt.Errorf("unexpected range request %q", header)
+			}
+			if opts.VersionID != "v-old" {
+				return nil, fmt.Errorf("unexpected version %q", opts.VersionID)
+			}
+			return io.NopCloser(strings.NewReader(pinned)), nil
+		},
+	}
+
+	fetcher := s3.NewFetcher(func(opts types.S3Options) (s3.Client, error) {
+		return mock, nil
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	tmpDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), tmpDir, types.Source{
+		S3: &types.S3Options{
+			Bucket:     "bucket",
+			Paths:      []string{"models/"},
+			VersionIDs: map[string]string{"models/large.bin": "v-old"},
+			Ranged:     &types.RangedDownloadOptions{PartSize: 64, Threshold: 100},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = obj.Processor(context.Background(), obj.Objects[0]); err != nil {
+		t.Fatalf("Processor failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "models/large.bin"))
+	if err != nil || string(data) != pinned {
+		t.Errorf("expected the complete pinned version, got %q (%v)", data, err)
+	}
+}
+
+func TestFetcher_Processor_RangedDownload(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("s3-ranged-", 20)
+	var rangeHeaders sync.Map
+	mock := &mockClient{
+		listObjectsFunc: func(_ context.Context, _ string, _ minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			ch := make(chan minio.ObjectInfo, 1)
+			ch <- minio.ObjectInfo{Key: "large.bin", Size: int64(len(content)), ETag: "abc123"}
+			close(ch)
+			return ch
+		},
+		getObjectFunc: func(_ context.Context, _, _ string, opts minio.GetObjectOptions) (io.ReadCloser, error) {
+			if got := opts.Header().Get("If-Match"); got != `"abc123"` {
+				return nil, fmt.Errorf("unexpected If-Match header %q", got)
+			}
+			var start, end int
+			header := opts.Header().Get("Range")
+			if _, err := fmt.Sscanf(header, "bytes=%d-%d", &start, &end); err != nil {
+				return nil, fmt.Errorf("unexpected range header %q", header)
+			}
+			rangeHeaders.Store(header, true)
+			return io.NopCloser(strings.NewReader(content[start : end+1])), nil
+		},
+	}
+
+	fetcher := s3.NewFetcher(func(opts types.S3Options) (s3.Client, error) {
+		return mock, nil
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	tmpDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), tmpDir, types.Source{
+		S3: &types.S3Options{
+			Bucket: "bucket",
+			Paths:  []string{"large.bin"},
+			Ranged: &types.RangedDownloadOptions{PartSize: 64, Threshold: 100},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = obj.Processor(context.Background(), obj.Objects[0]); err != nil {
+		t.Fatalf("Processor failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "large.bin"))
+	if err != nil {
+		t.Fatalf("failed to read downloaded file: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("content mismatch: got %q", data)
+	}
+
+	for _, want := range []string{"bytes=0-63", "bytes=64-127", "bytes=128-191", "bytes=192-199"} {
+		if _, ok := rangeHeaders.Load(want); !ok {
+			t.Errorf("expected range request %q", want)
+		}
+	}
+}
+
+func TestFetcher_Processor_RangedDownload_ObjectChanged(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockClient{
+		listObjectsFunc: func(_ context.Context, _ string, _ minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			ch := make(chan minio.ObjectInfo, 1)
+			ch <- minio.ObjectInfo{Key: "large.bin", Size: 200, ETag: "abc123"}
+			close(ch)
+			return ch
+		},
+		getObjectFunc: func(_ context.Context, _, _ string, _ minio.GetObjectOptions) (io.ReadCloser, error) {
+			return io.NopCloser(iotest.ErrReader(minio.ErrorResponse{StatusCode: http.StatusPreconditionFailed, Code: "PreconditionFailed"})), nil
+		},
+	}
+
+	fetcher := s3.NewFetcher(func(opts types.S3Options) (s3.Client, error) {
+		return mock, nil
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	obj, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{
+		S3: &types.S3Options{
+			Bucket: "bucket",
+			Paths:  []string{"large.bin"},
+			Ranged: &types.RangedDownloadOptions{PartSize: 64, Threshold: 100},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = obj.Processor(context.Background(), obj.Objects[0]); !errors.Is(err, s3.ErrObjectChanged) {
+		t.Fatalf("expected ErrObjectChanged, got %v", err)
+	}
+}
+
+func TestFetcher_Processor_Decompress(t *testing.T) {
+	t.Parallel()
+
+	const content = "id,label\n1,cat\n"
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte(content))
+	_ = gz.Close()
+
+	mock := &mockClient{
+		listObjectsFunc: func(_ context.Context, _ string, _ minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			ch := make(chan minio.ObjectInfo, 1)
+			ch <- minio.ObjectInfo{Key: "train.csv.gz", Size: int64(buf.Len())}
+			close(ch)
+			return ch
+		},
+		getObjectFunc: func(_ context.Context, _, _ string, opts minio.GetObjectOptions) (io.ReadCloser, error) {
+			if header := opts.Header().Get("Range"); header != "" {
+				return nil, fmt.Errorf("unexpected range request %q", header)
+			}
+			return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+		},
+	}
+
+	fetcher := s3.NewFetcher(func(opts types.S3Options) (s3.Client, error) {
+		return mock, nil
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	src := types.Source{
+		S3: &types.S3Options{
+			Bucket: "bucket",
+			Paths:  []string{"train.csv.gz"},
+			Ranged: &types.RangedDownloadOptions{Threshold: 1},
+		},
+		Decompress: true,
+	}
+	tmpDir := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), tmpDir, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = extract.Processor(src, obj.Processor)(context.Background(), obj.Objects[0]); err != nil {
+		t.Fatalf("Processor failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "train.csv"))
+	if err != nil {
+		t.Fatalf("failed to read decompressed file: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("content mismatch: got %q", data)
+	}
+}
+
+func TestFetcher_Fetch_IncludeExclude(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockClient{
+		listObjectsFunc: func(_ context.Context, _ string, _ minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			keys := []string{"data/train.csv", "data/README.md", "data/tests/sample.csv", "data/raw/valid.csv"}
+			ch := make(chan minio.ObjectInfo, len(keys))
+			for _, key := range keys {
+				ch <- minio.ObjectInfo{Key: key}
+			}
+			close(ch)
+			return ch
+		},
+	}
+
+	fetcher := s3.NewFetcher(func(opts types.S3Options) (s3.Client, error) {
+		return mock, nil
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	obj, err := fetcher.Fetch(context.Background(), t.TempDir(), types.Source{
+		S3:      &types.S3Options{Bucket: "bucket", Paths: []string{"data"}},
+		Include: []string{"**/*.csv"},
+		Exclude: []string{"tests/**"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var keys []string
+	for _, o := range obj.Objects {
+		keys = append(keys, o.ActualPath)
+	}
+	if want := []string{"data/train.csv", "data/raw/valid.csv"}; !slices.Equal(keys, want) {
+		t.Errorf("expected %v, got %v", want, keys)
+	}
+
+	_, err = fetcher.Fetch(context.Background(), t.TempDir(), types.Source{
+		S3:      &types.S3Options{Bucket: "bucket", Paths: []string{"data"}},
+		Include: []string{"[abc"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid glob pattern") {
+		t.Errorf("expected invalid pattern error, got %v", err)
+	}
+}
+
+func TestFetcher_Fetch_PathRules(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockClient{
+		listObjectsFunc: func(_ context.Context, _ string, _ minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			keys := []string{"data/2024/train.csv", "data/2025/valid.csv"}
+			ch := make(chan minio.ObjectInfo, len(keys))
+			for _, key := range keys {
+				ch <- minio.ObjectInfo{Key: key}
+			}
+			close(ch)
+			return ch
+		},
+	}
+
+	fetcher := s3.NewFetcher(func(opts types.S3Options) (s3.Client, error) {
+		return mock, nil
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	mountPath := t.TempDir()
+	obj, err := fetcher.Fetch(context.Background(), mountPath, types.Source{
+		S3:        &types.S3Options{Bucket: "bucket", Paths: []string{"data"}},
+		PathRules: []types.PathRule{{Flatten: true}, {AddPrefix: "csv"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var targets []string
+	for _, o := range obj.Objects {
+		targets = append(targets, utils.ResolveTargetPath(mountPath, o))
+	}
+	if want := []string{filepath.Join(mountPath, "csv", "train.csv"), filepath.Join(mountPath, "csv", "valid.csv")}; !slices.Equal(targets, want) {
+		t.Errorf("expected %v, got %v", want, targets)
+	}
+
+	_, err = fetcher.Fetch(context.Background(), mountPath, types.Source{
+		S3:        &types.S3Options{Bucket: "bucket", Paths: []string{"data"}},
+		PathRules: []types.PathRule{{Match: `\d+/.*`, Replace: "all.csv"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "more than one file") {
+		t.Errorf("expected collision error, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package sftp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const defaultPort = 22
+
+type ObjectInfo struct {
+	Key  string
+	Size int64
+}
+
+type Client interface {
+	ListObjects(root string) ([]ObjectInfo, error)
+	GetObject(path string) (io.ReadCloser, error)
+	Close() error
+}
+
+type ClientFactory func(ctx context.Context, opts types.SFTPOptions) (Client, error)
+
+type sftpClient struct {
+	ssh  *ssh.Client
+	sftp *sftp.Client
+}
+
+func NewClient(ctx context.Context, addr string, config *ssh.ClientConfig) (Client, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("sftp: failed to connect to %s: %w", addr, err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("sftp: ssh handshake with %s failed: %w", addr, err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, fmt.Errorf("sftp: failed to start sftp subsystem: %w", err)
+	}
+
+	return &sftpClient{ssh: sshClient, sftp: client}, nil
+}
+
+// ListObjects walks root recursively without following symlinks. A root that is a file yields itself.
+func (c *sftpClient) ListObjects(root string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	walker := c.sftp.Walk(root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return nil, fmt.Errorf("list error: %w", err)
+		}
+		if info := walker.Stat(); info.Mode().IsRegular() {
+			objects = append(objects, ObjectInfo{Key: walker.Path(), Size: info.Size()})
+		}
+	}
+	return objects, nil
+}
+
+func (c *sftpClient) GetObject(path string) (io.ReadCloser, error) {
+	return c.sftp.Open(path)
+}
+
+func (c *sftpClient) Close() error {
+	return errors.Join(c.sftp.Close(), c.ssh.Close())
+}
+
+func SFTPClientFactory(ctx context.Context, opts types.SFTPOptions) (Client, error) {
+	config, err := ClientConfig(opts, types.ResourcesDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(ctx, Address(opts), config)
+}
+
+func Address(opts types.SFTPOptions) string {
+	port := opts.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(opts.Host, strconv.Itoa(port))
+}
+
+// ClientConfig builds the ssh configuration from opts. Key and known_hosts files are relative to
+// resourcesDir. Host keys are verified unless InsecureIgnoreHostKey is set.
+func ClientConfig(opts types.SFTPOptions, resourcesDir string) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+
+	privateKey := []byte(utils.FromEnv(opts.PrivateKey))
+	if opts.PrivateKeyFile != "" {
+		data, err := os.ReadFile(filepath.Join(resourcesDir, opts.PrivateKeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("sftp: failed to read private key file: %w", err)
+		}
+		privateKey = data
+	}
+	if len(privateKey) > 0 {
+		signer, err := parsePrivateKey(privateKey, utils.FromEnv(opts.Passphrase))
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if opts.Password != "" {
+		auth = append(auth, ssh.Password(utils.FromEnv(opts.Password)))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("sftp: password or private key must be set")
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	switch {
+	case opts.KnownHostsFile != "":
+		callback, err := knownhosts.New(filepath.Join(resourcesDir, opts.KnownHostsFile))
+		if err != nil {
+			return nil, fmt.Errorf("sftp: failed to load known_hosts: %w", err)
+		}
+		hostKeyCallback = callback
+	case opts.InsecureIgnoreHostKey:
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, fmt.Errorf("sftp: knownHostsFile must be set unless insecureIgnoreHostKey is enabled")
+	}
+
+	return &ssh.ClientConfig{
+		User:            utils.FromEnv(opts.User),
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
+
+func parsePrivateKey(key []byte, passphrase string) (ssh.Signer, error) {
+	var signer ssh.Signer
+	var err error
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sftp: failed to parse private key: %w", err)
+	}
+	return signer, nil
+}
@@ -0,0 +1,287 @@
+package sftp_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	sftpf "github.com/AdamShannag/volare/pkg/fetcher/sftp"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+type testServer struct {
+	addr      string
+	hostKey   ssh.PublicKey
+	clientKey []byte
+}
+
+// startServer runs an in-process SSH server that accepts the given password or client key and
+// serves the local filesystem over the sftp subsystem.
+func startServer(t *testing.T, password string) testServer {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("failed to create host signer: %v", err)
+	}
+
+	_, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate client key: %v", err)
+	}
+	clientSigner, err := ssh.NewSignerFromKey(clientPriv)
+	if err != nil {
+		t.Fatalf("failed to create client signer: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatalf("failed to marshal client key: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() == "volare" && string(pass) == password {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "volare" && string(key.Marshal()) == string(clientSigner.PublicKey().Marshal()) {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go serveConn(conn, config)
+		}
+	}()
+
+	return testServer{
+		addr:      listener.Addr().String(),
+		hostKey:   hostSigner.PublicKey(),
+		clientKey: pem.EncodeToMemory(block),
+	}
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, acceptErr := newChannel.Accept()
+		if acceptErr != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if ok {
+					server, serverErr := sftp.NewServer(channel, sftp.ReadOnly())
+					if serverErr == nil {
+						_ = server.Serve()
+					}
+					_ = channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+}
+
+func options(t *testing.T, server testServer) types.SFTPOptions {
+	t.Helper()
+
+	host, port, err := net.SplitHostPort(server.addr)
+	if err != nil {
+		t.Fatalf("invalid address: %v", err)
+	}
+	opts := types.SFTPOptions{Host: host, User: "volare"}
+	for _, c := range port {
+		opts.Port = opts.Port*10 + int(c-'0')
+	}
+	return opts
+}
+
+func knownHosts(t *testing.T, dir, addr string, key ssh.PublicKey) {
+	t.Helper()
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, key)
+	if err := os.WriteFile(filepath.Join(dir, "known_hosts"), []byte(line+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write known_hosts: %v", err)
+	}
+}
+
+func TestClient_PrivateKeyAndKnownHosts(t *testing.T) {
+	server := startServer(t, "secret")
+	resources := t.TempDir()
+	knownHosts(t, resources, server.addr, server.hostKey)
+	if err := os.WriteFile(filepath.Join(resources, "id_ed25519"), server.clientKey, 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	remote := t.TempDir()
+	writeFiles(t, remote, map[string]string{"a.txt": "alpha", "sub/b.txt": "bravo", "sub/deep/c.txt": "charlie"})
+
+	opts := options(t, server)
+	opts.PrivateKeyFile = "id_ed25519"
+	opts.KnownHostsFile = "known_hosts"
+
+	config, err := sftpf.ClientConfig(opts, resources)
+	if err != nil {
+		t.Fatalf("failed to build config: %v", err)
+	}
+	client, err := sftpf.NewClient(context.Background(), sftpf.Address(opts), config)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	objects, err := client.ListObjects(remote)
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	var got []string
+	for _, o := range objects {
+		rel, _ := filepath.Rel(remote, o.Key)
+		got = append(got, rel)
+	}
+	slices.Sort(got)
+	if want := []string{"a.txt", "sub/b.txt", "sub/deep/c.txt"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	reader, err := client.GetObject(filepath.Join(remote, "sub/deep/c.txt"))
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+	data, _ := io.ReadAll(reader)
+	if string(data) != "charlie" {
+		t.Errorf("unexpected content %q", data)
+	}
+}
+
+func TestClient_Password(t *testing.T) {
+	server := startServer(t, "secret")
+
+	opts := options(t, server)
+	opts.Password = "secret"
+	opts.InsecureIgnoreHostKey = true
+
+	config, err := sftpf.ClientConfig(opts, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to build config: %v", err)
+	}
+	client, err := sftpf.NewClient(context.Background(), sftpf.Address(opts), config)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	_ = client.Close()
+
+	opts.Password = "wrong"
+	config, err = sftpf.ClientConfig(opts, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to build config: %v", err)
+	}
+	if _, err = sftpf.NewClient(context.Background(), sftpf.Address(opts), config); err == nil {
+		t.Error("expected authentication to fail")
+	}
+}
+
+func TestClient_HostKeyMismatch(t *testing.T) {
+	server := startServer(t, "secret")
+	other := startServer(t, "secret")
+
+	resources := t.TempDir()
+	knownHosts(t, resources, server.addr, other.hostKey)
+
+	opts := options(t, server)
+	opts.Password = "secret"
+	opts.KnownHostsFile = "known_hosts"
+
+	config, err := sftpf.ClientConfig(opts, resources)
+	if err != nil {
+		t.Fatalf("failed to build config: %v", err)
+	}
+	_, err = sftpf.NewClient(context.Background(), sftpf.Address(opts), config)
+	if err == nil || !strings.Contains(err.Error(), "key mismatch") {
+		t.Errorf("expected host key mismatch, got %v", err)
+	}
+}
+
+func TestClientConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts types.SFTPOptions
+	}{
+		{name: "no auth", opts: types.SFTPOptions{Host: "h", User: "u", InsecureIgnoreHostKey: true}},
+		{name: "no host key verification", opts: types.SFTPOptions{Host: "h", User: "u", Password: "p"}},
+		{name: "missing known_hosts", opts: types.SFTPOptions{Host: "h", User: "u", Password: "p", KnownHostsFile: "missing"}},
+		{name: "invalid private key", opts: types.SFTPOptions{Host: "h", User: "u", PrivateKey: "not a key", InsecureIgnoreHostKey: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sftpf.ClientConfig(tt.opts, t.TempDir()); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestAddress(t *testing.T) {
+	if got := sftpf.Address(types.SFTPOptions{Host: "example.com"}); got != "example.com:22" {
+		t.Errorf("expected default port, got %q", got)
+	}
+	if got := sftpf.Address(types.SFTPOptions{Host: "::1", Port: 2222}); got != "[::1]:2222" {
+		t.Errorf("unexpected address %q", got)
+	}
+}
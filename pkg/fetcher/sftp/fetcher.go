@@ -0,0 +1,108 @@
+package sftp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/AdamShannag/volare/pkg/extract"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+type Fetcher struct {
+	clientFactory ClientFactory
+	logger        *slog.Logger
+}
+
+func NewFetcher(clientFactory ClientFactory, logger *slog.Logger) fetcher.Fetcher {
+	return &Fetcher{
+		clientFactory: clientFactory,
+		logger:        logger,
+	}
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	client, err := f.clientFactory(ctx, *src.SFTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sftp client: %w", err)
+	}
+
+	var allObjects []types.ObjectToDownload
+	for _, p := range src.SFTP.Paths {
+		objects, listErr := client.ListObjects(p)
+		if listErr != nil {
+			if cerr := client.Close(); cerr != nil {
+				f.logger.Warn("error closing sftp client", "error", cerr)
+			}
+			return nil, fmt.Errorf("failed to list %q: %w", p, listErr)
+		}
+		for _, object := range objects {
+			allObjects = append(allObjects, types.ObjectToDownload{ActualPath: object.Key, Path: p, Size: object.Size})
+		}
+	}
+
+	if len(allObjects) == 0 {
+		f.logger.Info("no files found", "host", src.SFTP.Host, "paths", src.SFTP.Paths)
+	}
+
+	allObjects, err = utils.SelectObjects(mountPath, allObjects, src)
+	if err != nil {
+		if cerr := client.Close(); cerr != nil {
+			f.logger.Warn("error closing sftp client", "error", cerr)
+		}
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, job types.ObjectToDownload) error {
+			return f.download(ctx, client, mountPath, src.SFTP.Host, job)
+		},
+		Objects: allObjects,
+		Workers: src.SFTP.Workers,
+		Cleanup: func(ctx context.Context) error {
+			f.logger.Info("closing sftp connection", "host", src.SFTP.Host)
+			return client.Close()
+		},
+	}, nil
+}
+
+func (f *Fetcher) download(ctx context.Context, client Client, mountPath, host string, file types.ObjectToDownload) error {
+	targetPath := utils.ResolveTargetPath(mountPath, file)
+	f.logger.Info("downloading file", "host", host, "path", file.ActualPath)
+
+	reader, err := client.GetObject(file.ActualPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", file.ActualPath, err)
+	}
+	defer func() {
+		if cerr := reader.Close(); cerr != nil {
+			f.logger.Warn("error closing remote file", "path", file.ActualPath, "error", cerr)
+		}
+	}()
+
+	if err = os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
+	}
+
+	fh, err := extract.Create(ctx, targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", targetPath, err)
+	}
+	defer func() {
+		if cerr := fh.Close(); cerr != nil {
+			f.logger.Warn("error closing file", "file", targetPath, "error", cerr)
+		}
+	}()
+
+	if _, err = io.Copy(fh, reader); err != nil {
+		extract.Abort(fh, err)
+		return fmt.Errorf("failed to copy content to %q: %w", targetPath, err)
+	}
+
+	return nil
+}
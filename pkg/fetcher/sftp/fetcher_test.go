@@ -0,0 +1,160 @@
+package sftp_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	sftpf "github.com/AdamShannag/volare/pkg/fetcher/sftp"
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+type mockClient struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	listErr error
+	closed  bool
+}
+
+func (m *mockClient) ListObjects(root string) ([]sftpf.ObjectInfo, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+
+	var res []sftpf.ObjectInfo
+	for k, v := range m.objects {
+		if k == root || strings.HasPrefix(k, strings.TrimSuffix(root, "/")+"/") {
+			res = append(res, sftpf.ObjectInfo{Key: k, Size: int64(len(v))})
+		}
+	}
+	return res, nil
+}
+
+func (m *mockClient) GetObject(path string) (io.ReadCloser, error) {
+	data, ok := m.objects[path]
+	if !ok {
+		return nil, errors.New("file not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *mockClient) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func TestFetcher_Fetch_Success(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mock := &mockClient{
+		objects: map[string][]byte{
+			"/exports/daily/a.csv":     []byte("a"),
+			"/exports/daily/sub/b.csv": []byte("b"),
+			"/exports/readme.txt":      []byte("readme"),
+		},
+	}
+	clientFactory := func(ctx context.Context, opts types.SFTPOptions) (sftpf.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := sftpf.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	tmpDir := t.TempDir()
+	obj, err := fetcherInstance.Fetch(ctx, tmpDir, types.Source{
+		Type: types.SourceTypeSFTP,
+		SFTP: &types.SFTPOptions{Host: "sftp.example.com", Paths: []string{"/exports/daily", "/exports/readme.txt"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(obj.Objects) != 3 {
+		t.Fatalf("expected 3 objects, got %d", len(obj.Objects))
+	}
+
+	for _, o := range obj.Objects {
+		if pErr := obj.Processor(ctx, o); pErr != nil {
+			t.Fatalf("Processor failed for %q: %v", o.ActualPath, pErr)
+		}
+	}
+
+	for name, want := range map[string]string{"a.csv": "a", "sub/b.csv": "b", "readme.txt": "readme"} {
+		data, readErr := os.ReadFile(filepath.Join(tmpDir, name))
+		if readErr != nil || string(data) != want {
+			t.Errorf("unexpected content for %q: %q (%v)", name, data, readErr)
+		}
+	}
+
+	if err = obj.Cleanup(ctx); err != nil || !mock.closed {
+		t.Errorf("expected cleanup to close the client, got %v", err)
+	}
+}
+
+func TestFetcher_Fetch_ListError(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockClient{listErr: errors.New("permission denied")}
+	clientFactory := func(ctx context.Context, opts types.SFTPOptions) (sftpf.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := sftpf.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	_, err := fetcherInstance.Fetch(context.Background(), t.TempDir(), types.Source{
+		Type: types.SourceTypeSFTP,
+		SFTP: &types.SFTPOptions{Host: "h", Paths: []string{"/data"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to list") {
+		t.Errorf("expected list error, got %v", err)
+	}
+	if !mock.closed {
+		t.Error("expected client to be closed after a failed listing")
+	}
+}
+
+func TestFetcher_Fetch_FactoryError(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("dial failed")
+	clientFactory := func(ctx context.Context, opts types.SFTPOptions) (sftpf.Client, error) {
+		return nil, wantErr
+	}
+
+	fetcherInstance := sftpf.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	_, err := fetcherInstance.Fetch(context.Background(), t.TempDir(), types.Source{
+		Type: types.SourceTypeSFTP,
+		SFTP: &types.SFTPOptions{Host: "h", Paths: []string{"/data"}},
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("expected factory error, got %v", err)
+	}
+}
+
+func TestFetcher_Fetch_InvalidPatternClosesClient(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockClient{objects: map[string][]byte{"/data/a.txt": []byte("a")}}
+	clientFactory := func(ctx context.Context, opts types.SFTPOptions) (sftpf.Client, error) {
+		return mock, nil
+	}
+
+	fetcherInstance := sftpf.NewFetcher(clientFactory, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	_, err := fetcherInstance.Fetch(context.Background(), t.TempDir(), types.Source{
+		Type:    types.SourceTypeSFTP,
+		SFTP:    &types.SFTPOptions{Host: "h", Paths: []string{"/data"}},
+		Exclude: []string{"[abc"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid glob pattern") {
+		t.Errorf("expected invalid pattern error, got %v", err)
+	}
+	if !mock.closed {
+		t.Error("expected client to be closed after an invalid pattern")
+	}
+}
@@ -0,0 +1,297 @@
+package webdav
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/downloader"
+	"github.com/AdamShannag/volare/pkg/fetcher"
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+const methodPropfind = "PROPFIND"
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+  <d:prop>
+    <d:resourcetype/>
+    <d:getcontentlength/>
+    <d:getetag/>
+  </d:prop>
+</d:propfind>`
+
+type Option func(*Fetcher)
+
+type Fetcher struct {
+	client     *http.Client
+	downloader downloader.Downloader
+	logger     *slog.Logger
+}
+
+type Multistatus struct {
+	Responses []Response `xml:"DAV: response"`
+}
+
+type Response struct {
+	Href      string     `xml:"DAV: href"`
+	Propstats []Propstat `xml:"DAV: propstat"`
+}
+
+type Propstat struct {
+	Status string `xml:"DAV: status"`
+	Prop   Prop   `xml:"DAV: prop"`
+}
+
+type Prop struct {
+	ResourceType  ResourceType `xml:"DAV: resourcetype"`
+	ContentLength string       `xml:"DAV: getcontentlength"`
+	ETag          string       `xml:"DAV: getetag"`
+}
+
+type ResourceType struct {
+	Collection *struct{} `xml:"DAV: collection"`
+}
+
+// entry is a resource from a PROPFIND response, with its path relative to the source URL.
+type entry struct {
+	path       string
+	collection bool
+	size       int64
+	etag       string
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *Fetcher) {
+		f.client = client
+	}
+}
+
+func NewFetcher(downloader downloader.Downloader, logger *slog.Logger, opts ...Option) fetcher.Fetcher {
+	f := &Fetcher{
+		client:     http.DefaultClient,
+		downloader: downloader,
+		logger:     logger,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, mountPath string, src types.Source) (*fetcher.Object, error) {
+	davOpts := *src.WebDAV
+
+	base, err := url.Parse(strings.TrimSuffix(davOpts.URL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebDAV url %q: %w", davOpts.URL, err)
+	}
+
+	var filesToDownload []types.ObjectToDownload
+	for _, p := range davOpts.Paths {
+		root := cleanPath(p)
+		files, listErr := f.list(ctx, davOpts, base, root)
+		if listErr != nil {
+			return nil, fmt.Errorf("listing WebDAV path %q: %w", p, listErr)
+		}
+
+		for _, fl := range files {
+			filesToDownload = append(filesToDownload, types.ObjectToDownload{
+				Path:       root,
+				ActualPath: fl.path,
+				Version:    fl.etag,
+				Size:       fl.size,
+			})
+		}
+	}
+
+	if len(filesToDownload) == 0 {
+		f.logger.Info("no files found", "url", davOpts.URL, "paths", davOpts.Paths)
+	}
+
+	filesToDownload, err = utils.SelectObjects(mountPath, filesToDownload, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetcher.Object{
+		Processor: func(ctx context.Context, j types.ObjectToDownload) error {
+			return f.download(ctx, mountPath, j, davOpts, base)
+		},
+		Objects: filesToDownload,
+		Workers: davOpts.Workers,
+	}, nil
+}
+
+// list expands root into the files below it. With depth 1 every collection is listed in turn, since many
+// servers reject infinite depth requests.
+func (f *Fetcher) list(ctx context.Context, davOpts types.WebDAVOptions, base *url.URL, root string) ([]entry, error) {
+	depth := davOpts.Depth
+	if depth == "" {
+		depth = types.WebDAVDepthOne
+	}
+	if depth != types.WebDAVDepthOne && depth != types.WebDAVDepthInfinity {
+		return nil, fmt.Errorf("unsupported depth %q", depth)
+	}
+
+	return f.walk(ctx, davOpts, base, root, resourceURL(base, root), depth)
+}
+
+func (f *Fetcher) walk(ctx context.Context, davOpts types.WebDAVOptions, base *url.URL, root, target string, depth types.WebDAVDepth) ([]entry, error) {
+	entries, err := f.propfind(ctx, davOpts, base, target, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []entry
+	for _, e := range entries {
+		switch {
+		case !within(e.path, root):
+			f.logger.Warn("skipping resource outside of the requested path", "path", e.path, "root", root)
+		case !e.collection:
+			files = append(files, e)
+		case e.path != root && depth == types.WebDAVDepthOne:
+			// collections are requested with a trailing slash, which some servers otherwise redirect to
+			nested, nestedErr := f.walk(ctx, davOpts, base, e.path, resourceURL(base, e.path)+"/", depth)
+			if nestedErr != nil {
+				return nil, nestedErr
+			}
+			files = append(files, nested...)
+		}
+	}
+
+	return files, nil
+}
+
+func (f *Fetcher) propfind(ctx context.Context, davOpts types.WebDAVOptions, base *url.URL, target string, depth types.WebDAVDepth) ([]entry, error) {
+	req, err := http.NewRequestWithContext(ctx, methodPropfind, target, strings.NewReader(propfindBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Depth", string(depth))
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	for k, v := range authHeaders(davOpts) {
+		req.Header.Add(k, v)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WebDAV collection: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			f.logger.Warn("error closing response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("WebDAV server returned status %d", resp.StatusCode)
+	}
+
+	var ms Multistatus
+	if err = xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("failed to decode PROPFIND response: %w", err)
+	}
+
+	requestURL := resp.Request.URL
+	var entries []entry
+	for _, r := range ms.Responses {
+		e, ok, entryErr := toEntry(r, requestURL, base)
+		if entryErr != nil {
+			return nil, entryErr
+		}
+		if ok {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+func (f *Fetcher) download(ctx context.Context, mountPath string, file types.ObjectToDownload, davOpts types.WebDAVOptions, base *url.URL) error {
+	f.logger.Info("downloading file", slog.String("url", davOpts.URL), slog.String("file", file.ActualPath))
+	return f.downloader.Download(ctx, resourceURL(base, file.ActualPath), authHeaders(davOpts), utils.ResolveTargetPath(mountPath, file))
+}
+
+// toEntry resolves the href of r against the request and makes it relative to base. Resources outside
+// base are rejected so that a listing cannot write outside the target path.
+func toEntry(r Response, requestURL, base *url.URL) (entry, bool, error) {
+	var (
+		props Prop
+		found bool
+	)
+	for _, ps := range r.Propstats {
+		if strings.Contains(ps.Status, " 200 ") {
+			props, found = ps.Prop, true
+			break
+		}
+	}
+	if !found {
+		return entry{}, false, nil
+	}
+
+	href, err := url.Parse(strings.TrimSpace(r.Href))
+	if err != nil {
+		return entry{}, false, fmt.Errorf("invalid href %q: %w", r.Href, err)
+	}
+
+	resolved := path.Clean(requestURL.ResolveReference(href).Path)
+	basePath := path.Clean("/" + base.Path)
+	rel := strings.TrimPrefix(strings.TrimPrefix(resolved, basePath), "/")
+	if resolved != basePath && !strings.HasPrefix(resolved, strings.TrimSuffix(basePath, "/")+"/") {
+		return entry{}, false, fmt.Errorf("resource %q is outside of %q", resolved, basePath)
+	}
+
+	e := entry{
+		path:       rel,
+		collection: props.ResourceType.Collection != nil,
+		etag:       strings.Trim(strings.TrimPrefix(props.ETag, "W/"), `"`),
+	}
+	if props.ContentLength != "" {
+		if e.size, err = strconv.ParseInt(strings.TrimSpace(props.ContentLength), 10, 64); err != nil {
+			return entry{}, false, fmt.Errorf("invalid content length for %q: %w", rel, err)
+		}
+	}
+	return e, true, nil
+}
+
+func resourceURL(base *url.URL, p string) string {
+	if p == "" {
+		return base.String() + "/"
+	}
+
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return base.String() + "/" + strings.Join(segments, "/")
+}
+
+func within(p, root string) bool {
+	return root == "" || p == root || strings.HasPrefix(p, root+"/")
+}
+
+func cleanPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+func authHeaders(davOpts types.WebDAVOptions) map[string]string {
+	headers := map[string]string{}
+	switch {
+	case davOpts.Token != "":
+		headers["Authorization"] = "Bearer " + utils.FromEnv(davOpts.Token)
+	case davOpts.Password != "":
+		credentials := utils.FromEnv(davOpts.Username) + ":" + utils.FromEnv(davOpts.Password)
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+	return headers
+}
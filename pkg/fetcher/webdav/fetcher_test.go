@@ -0,0 +1,216 @@
+package webdav_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/downloader"
+	"github.com/AdamShannag/volare/pkg/fetcher/webdav"
+	"github.com/AdamShannag/volare/pkg/types"
+	xwebdav "golang.org/x/net/webdav"
+)
+
+const davPrefix = "/remote.php/dav/files/alice"
+
+func newServer(t *testing.T, files map[string]string, authorized func(*http.Request) bool) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	handler := &xwebdav.Handler{
+		Prefix:     davPrefix,
+		FileSystem: xwebdav.Dir(root),
+		LockSystem: xwebdav.NewMemLS(),
+	}
+
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.Header.Get("Depth")+" "+r.URL.Path)
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+
+	got := map[string]string{}
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, readErr := os.ReadFile(p)
+		if readErr != nil {
+			return readErr
+		}
+		rel, _ := filepath.Rel(root, p)
+		got[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	return got
+}
+
+func fetchAll(t *testing.T, server *httptest.Server, davOpts types.WebDAVOptions) map[string]string {
+	t.Helper()
+	ctx := context.Background()
+
+	f := webdav.NewFetcher(downloader.NewHTTPDownloader(), slog.New(slog.NewTextHandler(io.Discard, nil)), webdav.WithHTTPClient(server.Client()))
+	tmpDir := t.TempDir()
+	obj, err := f.Fetch(ctx, tmpDir, types.Source{Type: types.SourceTypeWEBDAV, WebDAV: &davOpts})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	for _, o := range obj.Objects {
+		if pErr := obj.Processor(ctx, o); pErr != nil {
+			t.Fatalf("Processor failed for %q: %v", o.ActualPath, pErr)
+		}
+	}
+	return readTree(t, tmpDir)
+}
+
+func TestFetcher_Fetch_DepthOneWithBasicAuth(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newServer(t, map[string]string{
+		"Documents/report.pdf":          "report",
+		"Documents/2024/q1 summary.txt": "q1",
+		"Documents/2024/deep/notes.md":  "notes",
+		"Photos/cat.jpg":                "cat",
+		"readme.md":                     "readme",
+	}, func(r *http.Request) bool {
+		user, pass, ok := r.BasicAuth()
+		return ok && user == "alice" && pass == "secret"
+	})
+
+	got := fetchAll(t, server, types.WebDAVOptions{
+		URL:      server.URL + davPrefix + "/",
+		Paths:    []string{"/Documents", "readme.md"},
+		Username: "alice",
+		Password: "secret",
+	})
+
+	want := map[string]string{
+		"report.pdf":          "report",
+		"2024/q1 summary.txt": "q1",
+		"2024/deep/notes.md":  "notes",
+		"readme.md":           "readme",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	for _, r := range *requests {
+		if strings.HasPrefix(r, "PROPFIND") && !strings.HasPrefix(r, "PROPFIND 1 ") {
+			t.Errorf("expected only depth 1 listings, got %q", r)
+		}
+	}
+}
+
+func TestFetcher_Fetch_DepthInfinityWithBearer(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newServer(t, map[string]string{
+		"data/a.csv":     "a",
+		"data/x/b.csv":   "b",
+		"data/x/y/c.csv": "c",
+	}, func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer app-token"
+	})
+
+	got := fetchAll(t, server, types.WebDAVOptions{
+		URL:   server.URL + davPrefix,
+		Paths: []string{"data"},
+		Depth: types.WebDAVDepthInfinity,
+		Token: "app-token",
+	})
+
+	want := map[string]string{"a.csv": "a", "x/b.csv": "b", "x/y/c.csv": "c"}
+	if !maps.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	var propfinds []string
+	for _, r := range *requests {
+		if strings.HasPrefix(r, "PROPFIND") {
+			propfinds = append(propfinds, r)
+		}
+	}
+	if !slices.Equal(propfinds, []string{"PROPFIND infinity " + davPrefix + "/data"}) {
+		t.Errorf("expected a single infinite depth listing, got %v", propfinds)
+	}
+}
+
+func TestFetcher_Fetch_Errors(t *testing.T) {
+	t.Parallel()
+
+	outside := `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:">
+  <d:response>
+    <d:href>/remote.php/dav/files/bob/secret.txt</d:href>
+    <d:propstat><d:prop><d:resourcetype/><d:getcontentlength>3</d:getcontentlength></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+  </d:response>
+</d:multistatus>`
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		depth   types.WebDAVDepth
+		wantErr string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: "status 401"},
+		{name: "href outside base", status: http.StatusMultiStatus, body: outside, wantErr: "outside of"},
+		{name: "invalid depth", status: http.StatusMultiStatus, depth: "0", wantErr: "unsupported depth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			f := webdav.NewFetcher(downloader.NewHTTPDownloader(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+			_, err := f.Fetch(context.Background(), t.TempDir(), types.Source{
+				Type:   types.SourceTypeWEBDAV,
+				WebDAV: &types.WebDAVOptions{URL: server.URL + davPrefix, Paths: []string{"docs"}, Depth: tt.depth},
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
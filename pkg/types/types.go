@@ -1,6 +1,8 @@
 package types
 
 import (
+	"encoding/json"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -9,12 +11,24 @@ const DefaultNumberOfWorkers = 2
 type SourceType string
 
 const (
-	SourceTypeHTTP   SourceType = "http"
-	SourceTypeS3     SourceType = "s3"
-	SourceTypeGITHUB SourceType = "github"
-	SourceTypeGITLAB SourceType = "gitlab"
-	SourceTypeGIT    SourceType = "git"
-	SourceTypeGCS    SourceType = "gcs"
+	SourceTypeHTTP        SourceType = "http"
+	SourceTypeS3          SourceType = "s3"
+	SourceTypeGITHUB      SourceType = "github"
+	SourceTypeGITLAB      SourceType = "gitlab"
+	SourceTypeGIT         SourceType = "git"
+	SourceTypeGCS         SourceType = "gcs"
+	SourceTypeGITEA       SourceType = "gitea"
+	SourceTypeBITBUCKET   SourceType = "bitbucket"
+	SourceTypeAZURE       SourceType = "azure"
+	SourceTypeOCI         SourceType = "oci"
+	SourceTypeIMAGE       SourceType = "image"
+	SourceTypeHUGGINGFACE SourceType = "huggingface"
+	SourceTypeSFTP        SourceType = "sftp"
+	SourceTypeFTP         SourceType = "ftp"
+	SourceTypeWEBDAV      SourceType = "webdav"
+	SourceTypeINLINE      SourceType = "inline"
+	SourceTypeCONFIGMAP   SourceType = "configmap"
+	SourceTypeSECRET      SourceType = "secret"
 )
 
 type VolarePopulator struct {
@@ -33,52 +47,202 @@ type Source struct {
 	Type       SourceType `json:"type"`
 	TargetPath string     `json:"targetPath"`
 
-	Http   *HttpOptions   `json:"http,omitempty"`
-	Gitlab *GitlabOptions `json:"gitlab,omitempty"`
-	GitHub *GitHubOptions `json:"github,omitempty"`
-	S3     *S3Options     `json:"s3,omitempty"`
-	Git    *GitOptions    `json:"git,omitempty"`
-	GCS    *GCSOptions    `json:"gcs,omitempty"`
+	Http        *HttpOptions        `json:"http,omitempty"`
+	Gitlab      *GitlabOptions      `json:"gitlab,omitempty"`
+	GitHub      *GitHubOptions      `json:"github,omitempty"`
+	S3          *S3Options          `json:"s3,omitempty"`
+	Git         *GitOptions         `json:"git,omitempty"`
+	GCS         *GCSOptions         `json:"gcs,omitempty"`
+	Gitea       *GiteaOptions       `json:"gitea,omitempty"`
+	Bitbucket   *BitbucketOptions   `json:"bitbucket,omitempty"`
+	Azure       *AzureOptions       `json:"azure,omitempty"`
+	OCI         *OCIOptions         `json:"oci,omitempty"`
+	Image       *ImageOptions       `json:"image,omitempty"`
+	HuggingFace *HuggingFaceOptions `json:"huggingface,omitempty"`
+	SFTP        *SFTPOptions        `json:"sftp,omitempty"`
+	FTP         *FTPOptions         `json:"ftp,omitempty"`
+	WebDAV      *WebDAVOptions      `json:"webdav,omitempty"`
+	Inline      *InlineOptions      `json:"inline,omitempty"`
+	ConfigMap   *KubeObjectOptions  `json:"configmap,omitempty"`
+	Secret      *KubeObjectOptions  `json:"secret,omitempty"`
+
+	Include    []string        `json:"include,omitempty"`
+	Exclude    []string        `json:"exclude,omitempty"`
+	PathRules  []PathRule      `json:"pathRules,omitempty"`
+	Extract    *ExtractOptions `json:"extract,omitempty"`
+	Decompress bool            `json:"decompress,omitempty"`
 }
 
 type HttpOptions struct {
-	URI     string            `json:"uri,omitempty"`
-	Headers map[string]string `json:"headers,omitempty"`
+	URI        string            `json:"uri,omitempty"`
+	Mirrors    []string          `json:"mirrors,omitempty"`
+	Files      []HttpFileOptions `json:"files,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Crawl      *HttpCrawlOptions `json:"crawl,omitempty"`
+	Workers    *int              `json:"workers,omitempty"`
+	TargetType PathType          `json:"targetType,omitempty"`
+}
+
+type HttpFileOptions struct {
+	URI     string   `json:"uri"`
+	Mirrors []string `json:"mirrors,omitempty"`
+}
+
+type HttpCrawlOptions struct {
+	MaxDepth int      `json:"maxDepth,omitempty"`
+	Include  []string `json:"include,omitempty"`
+	Exclude  []string `json:"exclude,omitempty"`
 }
 
 type GitlabOptions struct {
-	Host    string   `json:"host"`
-	Project string   `json:"project"`
-	Ref     string   `json:"ref"`
-	Paths   []string `json:"paths"`
-	Token   string   `json:"token,omitempty"`
-	Workers *int     `json:"workers,omitempty"`
+	Host      string                  `json:"host"`
+	Project   string                  `json:"project"`
+	Ref       string                  `json:"ref"`
+	Paths     []RepoPath              `json:"paths"`
+	Token     string                  `json:"token,omitempty"`
+	Workers   *int                    `json:"workers,omitempty"`
+	Artifacts *GitlabArtifactsOptions `json:"artifacts,omitempty"`
+	Package   *GitlabPackageOptions   `json:"package,omitempty"`
+}
+
+type GitlabArtifactsOptions struct {
+	Job  string `json:"job"`
+	Path string `json:"path,omitempty"`
+}
+
+type GitlabPackageOptions struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version"`
+	Files   []string `json:"files,omitempty"`
+}
+
+type PathType string
+
+const (
+	PathTypeFile PathType = "file"
+	PathTypeDir  PathType = "dir"
+)
+
+// RepoPath is a file or directory in a repository. It is written either as a plain string, where a trailing
+// slash marks a directory, or as an object with an explicit type. Paths of neither kind are looked up.
+type RepoPath struct {
+	Path string   `json:"path"`
+	Type PathType `json:"type,omitempty"`
+}
+
+func (p *RepoPath) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*p = RepoPath{Path: path}
+		return nil
+	}
+
+	type repoPath RepoPath
+	return json.Unmarshal(data, (*repoPath)(p))
 }
 
 type GitHubOptions struct {
-	Owner   string   `json:"owner"`
-	Repo    string   `json:"repo"`
-	Ref     string   `json:"ref"`
-	Paths   []string `json:"paths"`
-	Token   string   `json:"token,omitempty"`
-	Workers *int     `json:"workers,omitempty"`
+	Owner   string     `json:"owner"`
+	Repo    string     `json:"repo"`
+	Ref     string     `json:"ref"`
+	Paths   []RepoPath `json:"paths"`
+	Token   string     `json:"token,omitempty"`
+	Workers *int       `json:"workers,omitempty"`
+}
+
+type GiteaOptions struct {
+	Host    string     `json:"host"`
+	Owner   string     `json:"owner"`
+	Repo    string     `json:"repo"`
+	Ref     string     `json:"ref"`
+	Paths   []RepoPath `json:"paths"`
+	Token   string     `json:"token,omitempty"`
+	Workers *int       `json:"workers,omitempty"`
+}
+
+type BitbucketOptions struct {
+	Host      string     `json:"host,omitempty"`
+	Workspace string     `json:"workspace"`
+	Repo      string     `json:"repo"`
+	Ref       string     `json:"ref"`
+	Paths     []RepoPath `json:"paths"`
+	Username  string     `json:"username,omitempty"`
+	Password  string     `json:"password,omitempty"`
+	Token     string     `json:"token,omitempty"`
+	Workers   *int       `json:"workers,omitempty"`
 }
 
 type S3Options struct {
-	Endpoint        string   `json:"endpoint"`
-	Secure          bool     `json:"secure"`
-	Bucket          string   `json:"bucket"`
-	Paths           []string `json:"paths"`
-	Region          string   `json:"region"`
-	AccessKeyID     string   `json:"accessKeyId"`
-	SecretAccessKey string   `json:"secretAccessKey"`
-	SessionToken    string   `json:"sessionToken,omitempty"`
-	Workers         *int     `json:"workers,omitempty"`
+	Endpoint        string                  `json:"endpoint"`
+	Secure          bool                    `json:"secure"`
+	Bucket          string                  `json:"bucket"`
+	Paths           []string                `json:"paths"`
+	Region          string                  `json:"region"`
+	AccessKeyID     string                  `json:"accessKeyId"`
+	SecretAccessKey string                  `json:"secretAccessKey"`
+	SessionToken    string                  `json:"sessionToken,omitempty"`
+	Credentials     []S3CredentialsProvider `json:"credentials,omitempty"`
+	VersionIDs      map[string]string       `json:"versionIds,omitempty"`
+	AsOf            *metav1.Time            `json:"asOf,omitempty"`
+	Ranged          *RangedDownloadOptions  `json:"ranged,omitempty"`
+	Encryption      *S3EncryptionOptions    `json:"encryption,omitempty"`
+	Workers         *int                    `json:"workers,omitempty"`
+}
+
+type S3EncryptionOptions struct {
+	CustomerKey     string `json:"customerKey,omitempty"`
+	CustomerKeyFile string `json:"customerKeyFile,omitempty"`
+}
+
+type S3CredentialsType string
+
+const (
+	S3CredentialsEnv         S3CredentialsType = "env"
+	S3CredentialsFile        S3CredentialsType = "file"
+	S3CredentialsWebIdentity S3CredentialsType = "webIdentity"
+	S3CredentialsIAM         S3CredentialsType = "iam"
+	S3CredentialsStatic      S3CredentialsType = "static"
+)
+
+type S3CredentialsProvider struct {
+	Type      S3CredentialsType `json:"type"`
+	File      string            `json:"file,omitempty"`
+	Profile   string            `json:"profile,omitempty"`
+	TokenFile string            `json:"tokenFile,omitempty"`
+	RoleARN   string            `json:"roleArn,omitempty"`
+	Endpoint  string            `json:"endpoint,omitempty"`
+}
+
+type RangedDownloadOptions struct {
+	PartSize  int64 `json:"partSize,omitempty"`
+	Threshold int64 `json:"threshold,omitempty"`
+	Workers   *int  `json:"workers,omitempty"`
+}
+
+// PathRule rewrites the path of a file relative to the target path. Each rule sets exactly one of StripPrefix,
+// AddPrefix, Match or Flatten.
+type PathRule struct {
+	StripPrefix string `json:"stripPrefix,omitempty"`
+	AddPrefix   string `json:"addPrefix,omitempty"`
+	Match       string `json:"match,omitempty"`
+	Replace     string `json:"replace,omitempty"`
+	Flatten     bool   `json:"flatten,omitempty"`
+}
+
+// ExtractOptions unpacks downloaded archives into the directory they would have been written to.
+type ExtractOptions struct {
+	StripComponents int      `json:"stripComponents,omitempty"`
+	Include         []string `json:"include,omitempty"`
+	Exclude         []string `json:"exclude,omitempty"`
 }
 
 type ObjectToDownload struct {
 	ActualPath string
 	Path       string
+	Version    string
+	Size       int64
+	// TargetPath overrides the path resolved from ActualPath and Path, e.g. after path rules were applied.
+	TargetPath string
 }
 
 type GitOptions struct {
@@ -92,8 +256,151 @@ type GitOptions struct {
 }
 
 type GCSOptions struct {
-	Bucket          string   `json:"bucket"`
-	Paths           []string `json:"paths"`
-	CredentialsFile string   `json:"credentialsFile,omitempty"`
-	Workers         *int     `json:"workers,omitempty"`
+	Bucket                    string                 `json:"bucket"`
+	Paths                     []string               `json:"paths"`
+	Auth                      GCSAuthMode            `json:"auth,omitempty"`
+	CredentialsFile           string                 `json:"credentialsFile,omitempty"`
+	CredentialsJSON           string                 `json:"credentialsJson,omitempty"`
+	ImpersonateServiceAccount string                 `json:"impersonateServiceAccount,omitempty"`
+	Endpoint                  string                 `json:"endpoint,omitempty"`
+	Ranged                    *RangedDownloadOptions `json:"ranged,omitempty"`
+	Workers                   *int                   `json:"workers,omitempty"`
+}
+
+type GCSAuthMode string
+
+const (
+	GCSAuthAnonymous        GCSAuthMode = "anonymous"
+	GCSAuthCredentialsFile  GCSAuthMode = "credentialsFile"
+	GCSAuthCredentialsJSON  GCSAuthMode = "credentialsJson"
+	GCSAuthADC              GCSAuthMode = "adc"
+	GCSAuthWorkloadIdentity GCSAuthMode = "workloadIdentity"
+	GCSAuthImpersonate      GCSAuthMode = "impersonate"
+)
+
+type AzureOptions struct {
+	Account    string        `json:"account,omitempty"`
+	Container  string        `json:"container"`
+	Paths      []string      `json:"paths"`
+	Auth       AzureAuthMode `json:"auth,omitempty"`
+	SASToken   string        `json:"sasToken,omitempty"`
+	AccountKey string        `json:"accountKey,omitempty"`
+	ClientID   string        `json:"clientId,omitempty"`
+	Endpoint   string        `json:"endpoint,omitempty"`
+	Workers    *int          `json:"workers,omitempty"`
+}
+
+type AzureAuthMode string
+
+const (
+	AzureAuthAnonymous       AzureAuthMode = "anonymous"
+	AzureAuthSAS             AzureAuthMode = "sas"
+	AzureAuthSharedKey       AzureAuthMode = "sharedKey"
+	AzureAuthManagedIdentity AzureAuthMode = "managedIdentity"
+)
+
+type OCIOptions struct {
+	Reference  string   `json:"reference"`
+	MediaTypes []string `json:"mediaTypes,omitempty"`
+	Titles     []string `json:"titles,omitempty"`
+	Username   string   `json:"username,omitempty"`
+	Password   string   `json:"password,omitempty"`
+	Token      string   `json:"token,omitempty"`
+	Insecure   bool     `json:"insecure,omitempty"`
+	Workers    *int     `json:"workers,omitempty"`
+}
+
+type HuggingFaceOptions struct {
+	Repo     string              `json:"repo"`
+	Revision string              `json:"revision,omitempty"`
+	RepoType HuggingFaceRepoType `json:"repoType,omitempty"`
+	Include  []string            `json:"include,omitempty"`
+	Exclude  []string            `json:"exclude,omitempty"`
+	Token    string              `json:"token,omitempty"`
+	Endpoint string              `json:"endpoint,omitempty"`
+	Workers  *int                `json:"workers,omitempty"`
+}
+
+type HuggingFaceRepoType string
+
+const (
+	HuggingFaceRepoModel   HuggingFaceRepoType = "model"
+	HuggingFaceRepoDataset HuggingFaceRepoType = "dataset"
+	HuggingFaceRepoSpace   HuggingFaceRepoType = "space"
+)
+
+type SFTPOptions struct {
+	Host                  string   `json:"host"`
+	Port                  int      `json:"port,omitempty"`
+	User                  string   `json:"user"`
+	Password              string   `json:"password,omitempty"`
+	PrivateKey            string   `json:"privateKey,omitempty"`
+	PrivateKeyFile        string   `json:"privateKeyFile,omitempty"`
+	Passphrase            string   `json:"passphrase,omitempty"`
+	KnownHostsFile        string   `json:"knownHostsFile,omitempty"`
+	InsecureIgnoreHostKey bool     `json:"insecureIgnoreHostKey,omitempty"`
+	Paths                 []string `json:"paths"`
+	Workers               *int     `json:"workers,omitempty"`
+}
+
+type FTPOptions struct {
+	Host               string   `json:"host"`
+	Port               int      `json:"port,omitempty"`
+	User               string   `json:"user,omitempty"`
+	Password           string   `json:"password,omitempty"`
+	TLS                bool     `json:"tls,omitempty"`
+	InsecureSkipVerify bool     `json:"insecureSkipVerify,omitempty"`
+	DisableEPSV        bool     `json:"disableEPSV,omitempty"`
+	Paths              []string `json:"paths"`
+	Workers            *int     `json:"workers,omitempty"`
+}
+
+type WebDAVOptions struct {
+	URL      string      `json:"url"`
+	Paths    []string    `json:"paths"`
+	Depth    WebDAVDepth `json:"depth,omitempty"`
+	Username string      `json:"username,omitempty"`
+	Password string      `json:"password,omitempty"`
+	Token    string      `json:"token,omitempty"`
+	Workers  *int        `json:"workers,omitempty"`
+}
+
+type WebDAVDepth string
+
+const (
+	WebDAVDepthOne      WebDAVDepth = "1"
+	WebDAVDepthInfinity WebDAVDepth = "infinity"
+)
+
+type InlineOptions struct {
+	Content  string         `json:"content"`
+	Encoding InlineEncoding `json:"encoding,omitempty"`
+	Template bool           `json:"template,omitempty"`
+}
+
+type InlineEncoding string
+
+const (
+	InlineEncodingText    InlineEncoding = "text"
+	InlineEncodingBase64  InlineEncoding = "base64"
+	InlineEncodingDataURI InlineEncoding = "dataURI"
+)
+
+// KubeObjectOptions selects keys of a ConfigMap or Secret. The namespace is always the namespace of
+// the VolarePopulator, and the default service account of that namespace must be allowed to get the object.
+type KubeObjectOptions struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	Keys      []string `json:"keys,omitempty"`
+}
+
+type ImageOptions struct {
+	Reference string   `json:"reference"`
+	Platform  string   `json:"platform,omitempty"`
+	Paths     []string `json:"paths"`
+	Username  string   `json:"username,omitempty"`
+	Password  string   `json:"password,omitempty"`
+	Token     string   `json:"token,omitempty"`
+	Insecure  bool     `json:"insecure,omitempty"`
+	Workers   *int     `json:"workers,omitempty"`
 }
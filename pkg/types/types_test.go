@@ -0,0 +1,30 @@
+package types_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+func TestRepoPath_UnmarshalJSON(t *testing.T) {
+	var opts types.GitHubOptions
+	data := `{"paths": ["docs/", "README.md", {"path": "Makefile", "type": "file"}]}`
+	if err := json.Unmarshal([]byte(data), &opts); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want := []types.RepoPath{
+		{Path: "docs/"},
+		{Path: "README.md"},
+		{Path: "Makefile", Type: types.PathTypeFile},
+	}
+	if !slices.Equal(opts.Paths, want) {
+		t.Errorf("expected %v, got %v", want, opts.Paths)
+	}
+
+	if err := json.Unmarshal([]byte(`{"paths": [1]}`), &opts); err == nil {
+		t.Error("expected error for a path that is neither a string nor an object")
+	}
+}
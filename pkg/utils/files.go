@@ -13,6 +13,10 @@ import (
 )
 
 func ResolveTargetPath(mountPath string, file types.ObjectToDownload) string {
+	if file.TargetPath != "" {
+		return file.TargetPath
+	}
+
 	relPath := file.ActualPath
 
 	if strings.HasPrefix(file.ActualPath, file.Path+"/") {
@@ -24,8 +28,36 @@ func ResolveTargetPath(mountPath string, file types.ObjectToDownload) string {
 	return filepath.Join(mountPath, relPath)
 }
 
-func IsFile(p string) bool {
-	return !strings.HasSuffix(p, "/") && strings.Contains(filepath.Base(p), ".") && p != ""
+// PathType returns the type of p if it is set explicitly or implied by a trailing slash. An empty path is the
+// root directory. Any other path has an empty type, and has to be looked up by the caller.
+func PathType(p types.RepoPath) (types.PathType, error) {
+	switch p.Type {
+	case types.PathTypeFile, types.PathTypeDir:
+		return p.Type, nil
+	case "":
+		if p.Path == "" || strings.HasSuffix(p.Path, "/") {
+			return types.PathTypeDir, nil
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("path %q has unsupported type %q", p.Path, p.Type)
+	}
+}
+
+// InRepoPath reports whether the file name from a repository listing is selected by the path p of type kind.
+// A path of unknown type selects either the file itself or the files below it.
+func InRepoPath(name, p string, kind types.PathType) bool {
+	p = strings.Trim(p, "/")
+	switch {
+	case p == "":
+		return true
+	case kind == types.PathTypeFile:
+		return name == p
+	case kind == types.PathTypeDir:
+		return strings.HasPrefix(name, p+"/")
+	default:
+		return name == p || strings.HasPrefix(name, p+"/")
+	}
 }
 
 func ReadFilesAsBase64(root string) (map[string]string, error) {
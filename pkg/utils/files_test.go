@@ -76,29 +76,58 @@ func TestResolveTargetPath(t *testing.T) {
 	}
 }
 
-func TestIsFile(t *testing.T) {
+func TestPathType(t *testing.T) {
 	tests := []struct {
-		input    string
-		expected bool
+		input    types.RepoPath
+		expected types.PathType
 	}{
-		{input: "folder/file.txt", expected: true},
-		{input: "folder/file", expected: false},
-		{input: "folder/", expected: false},
-		{input: "", expected: false},
-		{input: "index.html", expected: true},
-		{input: "data/file.tar.gz", expected: true},
-		{input: "data/.hiddenfile", expected: true},
-		{input: "data/folder.with.dots/", expected: false},
+		{input: types.RepoPath{Path: "folder/file.txt"}, expected: ""},
+		{input: types.RepoPath{Path: "Makefile"}, expected: ""},
+		{input: types.RepoPath{Path: "folder/"}, expected: types.PathTypeDir},
+		{input: types.RepoPath{Path: ""}, expected: types.PathTypeDir},
+		{input: types.RepoPath{Path: "configs.d/"}, expected: types.PathTypeDir},
+		{input: types.RepoPath{Path: "Makefile", Type: types.PathTypeFile}, expected: types.PathTypeFile},
+		{input: types.RepoPath{Path: "data/file.tar.gz", Type: types.PathTypeDir}, expected: types.PathTypeDir},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := utils.IsFile(tt.input)
+		t.Run(tt.input.Path, func(t *testing.T) {
+			result, err := utils.PathType(tt.input)
+			if err != nil {
+				t.Fatalf("PathType(%v) returned error: %v", tt.input, err)
+			}
 			if result != tt.expected {
-				t.Errorf("IsFile(%q) = %v, expected %v", tt.input, result, tt.expected)
+				t.Errorf("PathType(%v) = %q, expected %q", tt.input, result, tt.expected)
 			}
 		})
 	}
+
+	if _, err := utils.PathType(types.RepoPath{Path: "a", Type: "link"}); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
+
+func TestInRepoPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		kind     types.PathType
+		expected bool
+	}{
+		{name: "Makefile", path: "Makefile", kind: "", expected: true},
+		{name: "docs/a.md", path: "docs", kind: "", expected: true},
+		{name: "docs.md", path: "docs", kind: "", expected: false},
+		{name: "docs/a.md", path: "/docs/", kind: types.PathTypeDir, expected: true},
+		{name: "docs", path: "docs", kind: types.PathTypeDir, expected: false},
+		{name: "docs/a.md", path: "docs", kind: types.PathTypeFile, expected: false},
+		{name: "any/file", path: "", kind: types.PathTypeDir, expected: true},
+	}
+
+	for _, tt := range tests {
+		if got := utils.InRepoPath(tt.name, tt.path, tt.kind); got != tt.expected {
+			t.Errorf("InRepoPath(%q, %q, %q) = %v, expected %v", tt.name, tt.path, tt.kind, got, tt.expected)
+		}
+	}
 }
 
 func TestReadFilesAsBase64(t *testing.T) {
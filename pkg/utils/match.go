@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// MatchPatterns reports whether p matches one of include, or include is empty, and none of exclude.
+// Patterns use doublestar syntax, where `**` matches across directories.
+func MatchPatterns(p string, include, exclude []string) bool {
+	matchAny := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := doublestar.Match(pattern, p); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(include) > 0 && !matchAny(include) {
+		return false
+	}
+	return !matchAny(exclude)
+}
+
+// ValidatePatterns returns an error for the first malformed pattern, since MatchPatterns treats it as matching
+// nothing.
+func ValidatePatterns(patterns ...[]string) error {
+	for _, list := range patterns {
+		for _, pattern := range list {
+			if !doublestar.ValidatePattern(pattern) {
+				return fmt.Errorf("invalid glob pattern %q", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// SelectObjects filters objects with the include and exclude patterns of src, and applies its path rules.
+func SelectObjects(mountPath string, objects []types.ObjectToDownload, src types.Source) ([]types.ObjectToDownload, error) {
+	filtered, err := FilterObjects(mountPath, objects, src.Include, src.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return MapObjects(mountPath, filtered, src.PathRules)
+}
+
+// SelectObjectsBy is SelectObjects for sources that resolve target paths themselves.
+func SelectObjectsBy(mountPath string, objects []types.ObjectToDownload, src types.Source, target func(*types.ObjectToDownload) *string) ([]types.ObjectToDownload, error) {
+	filtered, err := FilterObjectsBy(mountPath, objects, src.Include, src.Exclude, target)
+	if err != nil {
+		return nil, err
+	}
+	return MapObjectsBy(mountPath, filtered, src.PathRules, target)
+}
+
+// FilterObjects keeps the objects whose path as resolved by ResolveTargetPath, relative to mountPath, matches
+// include and exclude.
+func FilterObjects(mountPath string, objects []types.ObjectToDownload, include, exclude []string) ([]types.ObjectToDownload, error) {
+	return FilterObjectsBy(mountPath, objects, include, exclude, func(o *types.ObjectToDownload) *string {
+		p := ResolveTargetPath(mountPath, *o)
+		return &p
+	})
+}
+
+// FilterObjectsBy is FilterObjects for sources that resolve target paths themselves. target returns the field
+// that holds the target path of an object. A file written to mountPath itself is matched by its name.
+func FilterObjectsBy(mountPath string, objects []types.ObjectToDownload, include, exclude []string, target func(*types.ObjectToDownload) *string) ([]types.ObjectToDownload, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return objects, nil
+	}
+	if err := ValidatePatterns(include, exclude); err != nil {
+		return nil, err
+	}
+
+	var filtered []types.ObjectToDownload
+	for _, o := range objects {
+		p := *target(&o)
+		rel, err := filepath.Rel(mountPath, p)
+		if err != nil || rel == "." {
+			rel = filepath.Base(p)
+		}
+		if MatchPatterns(filepath.ToSlash(rel), include, exclude) {
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered, nil
+}
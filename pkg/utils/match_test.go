@@ -0,0 +1,80 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+func TestMatchPatterns(t *testing.T) {
+	tests := []struct {
+		path     string
+		include  []string
+		exclude  []string
+		expected bool
+	}{
+		{"a/b/c.txt", nil, nil, true},
+		{"a/b/c.txt", []string{"**/*.txt"}, nil, true},
+		{"a/b/c.txt", []string{"*.txt"}, nil, false},
+		{"c.txt", []string{"*.txt"}, nil, true},
+		{"a/b/c.txt", nil, []string{"a/**"}, false},
+		{"a/b/c.txt", []string{"**/*.txt"}, []string{"a/b/*"}, false},
+		{"a/b/c.bin", []string{"**/*.txt", "**/*.bin"}, []string{"x/**"}, true},
+	}
+
+	for _, tt := range tests {
+		if got := utils.MatchPatterns(tt.path, tt.include, tt.exclude); got != tt.expected {
+			t.Errorf("MatchPatterns(%q, %v, %v) = %v, want %v", tt.path, tt.include, tt.exclude, got, tt.expected)
+		}
+	}
+}
+
+func TestFilterObjects(t *testing.T) {
+	objects := []types.ObjectToDownload{
+		{ActualPath: "repo/docs/README.md", Path: "repo"},
+		{ActualPath: "repo/src/main.go", Path: "repo"},
+		{ActualPath: "repo/tests/main_test.go", Path: "repo"},
+		{ActualPath: "CHANGELOG.md", Path: "CHANGELOG.md"},
+	}
+
+	got, err := utils.FilterObjects("/mnt/data", objects, nil, []string{"**/*.md", "tests/**"})
+	if err != nil {
+		t.Fatalf("FilterObjects failed: %v", err)
+	}
+	if len(got) != 1 || got[0].ActualPath != "repo/src/main.go" {
+		t.Errorf("unexpected objects after exclude: %v", got)
+	}
+
+	got, _ = utils.FilterObjects("/mnt/data", objects, []string{"*.md"}, nil)
+	if len(got) != 1 || got[0].ActualPath != "CHANGELOG.md" {
+		t.Errorf("unexpected objects after include: %v", got)
+	}
+
+	if got, _ = utils.FilterObjects("/mnt/data", objects, nil, nil); len(got) != len(objects) {
+		t.Errorf("expected all objects without patterns, got %v", got)
+	}
+
+	if _, err = utils.FilterObjects("/mnt/data", objects, []string{"[abc"}, nil); err == nil || !strings.Contains(err.Error(), "invalid glob pattern") {
+		t.Errorf("expected invalid pattern error, got %v", err)
+	}
+}
+
+func TestFilterObjectsBy(t *testing.T) {
+	objects := []types.ObjectToDownload{
+		{ActualPath: "https://example.com/model.bin", Path: "/mnt/data/model.bin"},
+		{ActualPath: "https://example.com/config.json", Path: "/mnt/data/config.json"},
+		{ActualPath: "https://example.com/weights", Path: "/mnt/data"},
+	}
+
+	got, err := utils.FilterObjectsBy("/mnt/data", objects, []string{"*.json", "data"}, nil, func(o *types.ObjectToDownload) *string {
+		return &o.Path
+	})
+	if err != nil {
+		t.Fatalf("FilterObjectsBy failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Path != "/mnt/data/config.json" || got[1].Path != "/mnt/data" {
+		t.Errorf("unexpected objects: %v", got)
+	}
+}
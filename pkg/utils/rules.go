@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AdamShannag/volare/pkg/types"
+)
+
+type PathMapper struct {
+	rules []pathRule
+}
+
+type pathRule struct {
+	types.PathRule
+	match *regexp.Regexp
+}
+
+func NewPathMapper(rules []types.PathRule) (*PathMapper, error) {
+	m := &PathMapper{}
+	for i, r := range rules {
+		set := 0
+		for _, ok := range []bool{r.StripPrefix != "", r.AddPrefix != "", r.Match != "", r.Flatten} {
+			if ok {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("path rule %d must set exactly one of stripPrefix, addPrefix, match or flatten", i)
+		}
+		if r.Replace != "" && r.Match == "" {
+			return nil, fmt.Errorf("path rule %d sets replace without match", i)
+		}
+
+		rule := pathRule{PathRule: r}
+		if r.Match != "" {
+			re, err := regexp.Compile(r.Match)
+			if err != nil {
+				return nil, fmt.Errorf("path rule %d has invalid match: %w", i, err)
+			}
+			rule.match = re
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// Map applies the rules in order to target relative to mountPath. A target equal to mountPath names a single
+// file chosen through targetPath, and is returned unchanged.
+func (m *PathMapper) Map(mountPath, target string) (string, error) {
+	rel, err := filepath.Rel(mountPath, target)
+	if err != nil || rel == "." {
+		return target, nil
+	}
+
+	p := filepath.ToSlash(rel)
+	for _, r := range m.rules {
+		switch {
+		case r.StripPrefix != "":
+			prefix := strings.Trim(r.StripPrefix, "/")
+			if rest, ok := strings.CutPrefix(p, prefix+"/"); ok {
+				p = rest
+			}
+		case r.AddPrefix != "":
+			p = path.Join(r.AddPrefix, p)
+		case r.match != nil:
+			p = r.match.ReplaceAllString(p, r.Replace)
+		case r.Flatten:
+			p = path.Base(p)
+		}
+	}
+
+	p = path.Clean(p)
+	if p == "." || !filepath.IsLocal(p) {
+		return "", fmt.Errorf("path rules map %q outside of the target path", rel)
+	}
+	return filepath.Join(mountPath, filepath.FromSlash(p)), nil
+}
+
+// MapObjects applies rules to the target path of each object as resolved by ResolveTargetPath, and stores the
+// result in TargetPath.
+func MapObjects(mountPath string, objects []types.ObjectToDownload, rules []types.PathRule) ([]types.ObjectToDownload, error) {
+	return MapObjectsBy(mountPath, objects, rules, func(o *types.ObjectToDownload) *string {
+		o.TargetPath = ResolveTargetPath(mountPath, *o)
+		return &o.TargetPath
+	})
+}
+
+// MapObjectsBy is MapObjects for sources that resolve target paths themselves. target returns the field that
+// holds the target path of an object.
+func MapObjectsBy(mountPath string, objects []types.ObjectToDownload, rules []types.PathRule, target func(*types.ObjectToDownload) *string) ([]types.ObjectToDownload, error) {
+	if len(rules) == 0 {
+		return objects, nil
+	}
+
+	mapper, err := NewPathMapper(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := make([]types.ObjectToDownload, 0, len(objects))
+	seen := make(map[string]struct{}, len(objects))
+	for _, o := range objects {
+		p := target(&o)
+		dest, mapErr := mapper.Map(mountPath, *p)
+		if mapErr != nil {
+			return nil, mapErr
+		}
+		if _, ok := seen[dest]; ok {
+			return nil, fmt.Errorf("more than one file would be written to %q", dest)
+		}
+		seen[dest] = struct{}{}
+
+		*p = dest
+		mapped = append(mapped, o)
+	}
+	return mapped, nil
+}
@@ -0,0 +1,127 @@
+package utils_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdamShannag/volare/pkg/types"
+	"github.com/AdamShannag/volare/pkg/utils"
+)
+
+func TestPathMapper_Map(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  []types.PathRule
+		target string
+		want   string
+	}{
+		{"no rules", nil, "a/b.txt", "a/b.txt"},
+		{"strip prefix", []types.PathRule{{StripPrefix: "/src/"}}, "src/main/app.go", "main/app.go"},
+		{"strip missing prefix", []types.PathRule{{StripPrefix: "src"}}, "srcs/app.go", "srcs/app.go"},
+		{"add prefix", []types.PathRule{{AddPrefix: "vendor/lib"}}, "app.go", "vendor/lib/app.go"},
+		{"regex", []types.PathRule{{Match: `^v(\d+)/(.*)\.json$`, Replace: "$2-v$1.json"}}, "v2/config.json", "config-v2.json"},
+		{"flatten", []types.PathRule{{Flatten: true}}, "a/b/c/d.bin", "d.bin"},
+		{"in order", []types.PathRule{{StripPrefix: "models"}, {AddPrefix: "weights"}}, "models/llama/model.bin", "weights/llama/model.bin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := utils.NewPathMapper(tt.rules)
+			if err != nil {
+				t.Fatalf("NewPathMapper failed: %v", err)
+			}
+			got, err := m.Map("/mnt/data", filepath.Join("/mnt/data", tt.target))
+			if err != nil {
+				t.Fatalf("Map failed: %v", err)
+			}
+			if want := filepath.Join("/mnt/data", tt.want); got != want {
+				t.Errorf("expected %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestPathMapper_Map_TargetPathUnchanged(t *testing.T) {
+	m, err := utils.NewPathMapper([]types.PathRule{{AddPrefix: "sub"}})
+	if err != nil {
+		t.Fatalf("NewPathMapper failed: %v", err)
+	}
+	got, err := m.Map("/mnt/data/app.yaml", "/mnt/data/app.yaml")
+	if err != nil || got != "/mnt/data/app.yaml" {
+		t.Errorf("expected target path to be unchanged, got %q, %v", got, err)
+	}
+}
+
+func TestPathMapper_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []types.PathRule
+		want  string
+	}{
+		{"no action", []types.PathRule{{}}, "exactly one"},
+		{"two actions", []types.PathRule{{StripPrefix: "a", Flatten: true}}, "exactly one"},
+		{"replace without match", []types.PathRule{{AddPrefix: "a", Replace: "b"}}, "replace without match"},
+		{"invalid regex", []types.PathRule{{Match: "("}}, "invalid match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := utils.NewPathMapper(tt.rules)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	m, err := utils.NewPathMapper([]types.PathRule{{AddPrefix: "../escape"}})
+	if err != nil {
+		t.Fatalf("NewPathMapper failed: %v", err)
+	}
+	if _, err = m.Map("/mnt/data", "/mnt/data/a.txt"); err == nil || !strings.Contains(err.Error(), "outside of the target path") {
+		t.Errorf("expected escape error, got %v", err)
+	}
+}
+
+func TestMapObjects(t *testing.T) {
+	objects := []types.ObjectToDownload{
+		{ActualPath: "repo/docs/README.md", Path: "repo"},
+		{ActualPath: "repo/src/main.go", Path: "repo"},
+	}
+
+	got, err := utils.MapObjects("/mnt/data", objects, []types.PathRule{{StripPrefix: "repo"}})
+	if err != nil {
+		t.Fatalf("MapObjects failed: %v", err)
+	}
+	if want := filepath.Join("/mnt/data", "docs", "README.md"); got[0].TargetPath != want {
+		t.Errorf("expected %q, got %q", want, got[0].TargetPath)
+	}
+	if want := filepath.Join("/mnt/data", "src", "main.go"); utils.ResolveTargetPath("/mnt/data", got[1]) != want {
+		t.Errorf("expected ResolveTargetPath to honor the mapped path %q", want)
+	}
+
+	_, err = utils.MapObjects("/mnt/data", objects, []types.PathRule{{Match: `.*`, Replace: "same"}})
+	if err == nil || !strings.Contains(err.Error(), "more than one file") {
+		t.Errorf("expected collision error, got %v", err)
+	}
+}
+
+func TestMapObjectsBy(t *testing.T) {
+	objects := []types.ObjectToDownload{
+		{ActualPath: "https://example.com/a/model.bin", Path: "/mnt/data/a/model.bin"},
+		{ActualPath: "https://example.com/b/config.json", Path: "/mnt/data/b/config.json"},
+	}
+
+	got, err := utils.MapObjectsBy("/mnt/data", objects, []types.PathRule{{Flatten: true}}, func(o *types.ObjectToDownload) *string {
+		return &o.Path
+	})
+	if err != nil {
+		t.Fatalf("MapObjectsBy failed: %v", err)
+	}
+	if got[0].Path != filepath.Join("/mnt/data", "model.bin") || got[1].Path != filepath.Join("/mnt/data", "config.json") {
+		t.Errorf("unexpected mapped paths: %v", got)
+	}
+	if objects[0].Path != "/mnt/data/a/model.bin" {
+		t.Errorf("expected input objects to be left untouched, got %q", objects[0].Path)
+	}
+}
@@ -92,6 +92,9 @@ func RunPool[T any](ctx context.Context, items []T, workers *int, processor func
 	if workers != nil {
 		numWorkers = *workers
 	}
+	if numWorkers < 1 {
+		return fmt.Errorf("workers must be at least 1, got %d", numWorkers)
+	}
 
 	pool := New(ctx, numWorkers, len(items), processor)
 	pool.Start()
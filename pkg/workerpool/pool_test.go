@@ -231,3 +231,17 @@ func TestRunPool_NilWorkers_UsesDefault(t *testing.T) {
 		t.Fatalf("expected %d processed, got %d", len(items), got)
 	}
 }
+
+func TestRunPool_RejectsNonPositiveWorkers(t *testing.T) {
+	t.Parallel()
+
+	for _, workers := range []int{0, -1} {
+		err := workerpool.RunPool(context.Background(), []int{1}, &workers, func(ctx context.Context, i int) error {
+			t.Fatal("processor should not be called")
+			return nil
+		})
+		if err == nil {
+			t.Fatalf("expected error for %d workers", workers)
+		}
+	}
+}